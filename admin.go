@@ -0,0 +1,235 @@
+// admin.go (Perintah /admin untuk operator bot)
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// isBotAdmin mengizinkan member dengan role admin (ADMIN_ROLE_ID), atau izin Manage Server
+// khusus di server operator. Admin server lain tidak boleh mengubah state global bot.
+func isBotAdmin(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	if cfg.AdminRoleID != "" {
+		for _, roleID := range i.Member.Roles {
+			if roleID == cfg.AdminRoleID {
+				return true
+			}
+		}
+	}
+	if cfg.OperatorGuildID == "" || i.GuildID != cfg.OperatorGuildID {
+		return false
+	}
+	return i.Member.Permissions&discordgo.PermissionManageGuild != 0
+}
+
+func adminCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isBotAdmin(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: "❌ Anda tidak punya akses ke perintah ini.", Flags: discordgo.MessageFlagsEphemeral},
+		})
+		return
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		logFor(i).Error("Could not defer /admin", "error", err)
+		return
+	}
+
+	sub := i.ApplicationCommandData().Options[0]
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		options[opt.Name] = opt
+	}
+	logFor(i).Info("Admin command invoked", "subcommand", sub.Name)
+
+	switch sub.Name {
+	case "check":
+		if opt, ok := options["manga_id"]; ok {
+			adminCheckOne(s, i, opt.StringValue())
+			return
+		}
+		if checkRunning.Load() {
+			editInteractionContent(s, i, "⏳ Pengecekan update sedang berjalan.")
+			return
+		}
+		// Dijalankan oleh checker goroutine agar ikut ditunggu saat shutdown
+		select {
+		case forceCheckRequests <- checkRequest{}:
+			editInteractionContent(s, i, "🔄 Pengecekan update untuk semua series dimulai. Lihat `/admin status` untuk hasilnya.")
+		default:
+			editInteractionContent(s, i, "⏳ Pengecekan update penuh sudah dijadwalkan.")
+		}
+	case "status":
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Embeds: &[]*discordgo.MessageEmbed{createAdminStatusEmbed()}})
+	case "top":
+		limit := 10
+		if opt, ok := options["jumlah"]; ok {
+			limit = int(opt.IntValue())
+		}
+		adminTopWatched(s, i, limit)
+	case "reset":
+		var chapterID string
+		if opt, ok := options["chapter_id"]; ok {
+			chapterID = opt.StringValue()
+		}
+		adminResetChapter(s, i, options["manga_id"].StringValue(), chapterID)
+	case "test":
+		embed := createNotificationEmbed(
+			&Manga{ID: "test", Title: "Test Notifikasi", CoverURL: "https://i.imgur.com/R4Ifj2p.png"},
+			&Chapter{ID: "test", Number: 1, ReleaseDate: time.Now().Format(time.RFC3339)},
+		)
+		_, err := s.ChannelMessageSendComplex(cfg.UpdateChannelID, &discordgo.MessageSend{
+			Content:         fmt.Sprintf("🧪 Test notifikasi dikirim oleh <@%s>.", interactionUserID(i)),
+			Embed:           embed,
+			AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+		})
+		if err != nil {
+			logFor(i).Error("Failed to send test notification", "error", err)
+			editInteractionContent(s, i, fmt.Sprintf("❌ Gagal mengirim test notifikasi: %v", err))
+			return
+		}
+		editInteractionContent(s, i, fmt.Sprintf("✅ Test notifikasi terkirim ke <#%s>.", cfg.UpdateChannelID))
+	}
+}
+
+func adminCheckOne(s *discordgo.Session, i *discordgo.InteractionCreate, mangaID string) {
+	knownChapterID, err := getLatestKnownChapter(db, mangaID)
+	if err == sql.ErrNoRows {
+		editInteractionContent(s, i, fmt.Sprintf("❌ Manga `%s` tidak ada di watchlist siapa pun.", mangaID))
+		return
+	}
+	if err != nil {
+		logFor(i).Error("Failed to get latest known chapter", "manga_id", mangaID, "error", err)
+		editInteractionContent(s, i, "Gagal membaca database.")
+		return
+	}
+	if checkRunning.Load() {
+		editInteractionContent(s, i, "⏳ Pengecekan update sedang berjalan, coba lagi nanti.")
+		return
+	}
+	// Dijalankan oleh checker goroutine agar tidak terpotong db.Close saat shutdown
+	req := checkRequest{MangaID: mangaID, KnownChapterID: knownChapterID, Result: make(chan checkResult, 1)}
+	select {
+	case forceCheckRequests <- req:
+	default:
+		editInteractionContent(s, i, "⏳ Pengecekan update lain sudah dijadwalkan, coba lagi nanti.")
+		return
+	}
+	var result checkResult
+	select {
+	case result = <-req.Result:
+	case <-appCtx.Done():
+		editInteractionContent(s, i, "⏳ Bot sedang dimatikan, pengecekan dibatalkan.")
+		return
+	}
+	if err := result.Err; err != nil {
+		editInteractionContent(s, i, fmt.Sprintf("❌ Pengecekan gagal: %v", err))
+		return
+	}
+	if result.Found {
+		editInteractionContent(s, i, fmt.Sprintf("🔔 Chapter baru ditemukan untuk `%s`, notifikasi telah dikirim.", mangaID))
+		return
+	}
+	editInteractionContent(s, i, fmt.Sprintf("✅ Tidak ada chapter baru untuk `%s`.", mangaID))
+}
+
+func createAdminStatusEmbed() *discordgo.MessageEmbed {
+	health.mu.Lock()
+	lastCheckAt, lastSuccessAt := health.lastCheckAt, health.lastSuccessAt
+	lastCheckErr, lastFailures := health.lastCheckErr, health.lastFailures
+	health.mu.Unlock()
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return fmt.Sprintf("<t:%d:R>", t.Unix())
+	}
+	state := "💤 Idle"
+	if checkRunning.Load() {
+		state = "🔄 Sedang berjalan"
+	}
+	lastResult := fmt.Sprintf("%d series gagal", lastFailures)
+	if lastCheckErr != "" {
+		lastResult = "❌ " + truncateTitle(lastCheckErr, 200)
+	}
+	errorRate, requests := health.apiErrorRate()
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Status Checker",
+		Color: 0x00bfff,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Status", Value: state, Inline: true},
+			{Name: "Cek Terakhir", Value: formatTime(lastCheckAt), Inline: true},
+			{Name: "Sukses Terakhir", Value: formatTime(lastSuccessAt), Inline: true},
+			{Name: "Hasil Terakhir", Value: lastResult, Inline: true},
+			{Name: "Error API (15 menit)", Value: fmt.Sprintf("%.0f%% dari %d request", errorRate*100, requests), Inline: true},
+		},
+	}
+
+	var lines []string
+	for _, e := range health.lastErrors() {
+		lines = append(lines, fmt.Sprintf("%s `%s`: %s", formatTime(e.At), e.MangaID, truncateTitle(e.Error, 80)))
+	}
+	if len(lines) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Error Terakhir", Value: truncateTitle(strings.Join(lines, "\n"), 1024)})
+	}
+	return embed
+}
+
+func adminTopWatched(s *discordgo.Session, i *discordgo.InteractionCreate, limit int) {
+	counts, err := GetMostWatchedManga(db, limit)
+	if err != nil {
+		logFor(i).Error("Failed to get most watched manga", "error", err)
+		editInteractionContent(s, i, "Gagal membaca database.")
+		return
+	}
+	if len(counts) == 0 {
+		editInteractionContent(s, i, "📚 Belum ada series yang di-watch.")
+		return
+	}
+	var lines []string
+	for idx, c := range counts {
+		lines = append(lines, fmt.Sprintf("`%d.` **%s** — %d watcher (`%s`)", idx+1, truncateTitle(c.MangaTitle, 50), c.Watchers, c.MangaID))
+	}
+	embeds := []*discordgo.MessageEmbed{{
+		Title:       "Series Paling Banyak Di-watch",
+		Description: strings.Join(lines, "\n"),
+		Color:       0x00bfff,
+	}}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Embeds: &embeds})
+}
+
+func adminResetChapter(s *discordgo.Session, i *discordgo.InteractionCreate, mangaID, chapterID string) {
+	if _, err := getLatestKnownChapter(db, mangaID); err != nil {
+		editInteractionContent(s, i, fmt.Sprintf("❌ Manga `%s` tidak ada di watchlist siapa pun.", mangaID))
+		return
+	}
+	// Tanpa chapter_id, pakai chapter terbaru dari API agar tidak ada notifikasi ulang
+	if chapterID == "" {
+		latestChapter, err := GetLatestChapter(mangaID)
+		if err != nil {
+			editInteractionContent(s, i, fmt.Sprintf("❌ Gagal mengambil chapter terbaru: %v", err))
+			return
+		}
+		chapterID = latestChapter.ID
+	}
+	if err := updateLatestKnownChapter(db, mangaID, chapterID); err != nil {
+		logFor(i).Error("Failed to reset latest known chapter", "manga_id", mangaID, "error", err)
+		editInteractionContent(s, i, "Gagal menyimpan perubahan.")
+		return
+	}
+	logFor(i).Info("Latest known chapter reset", "manga_id", mangaID, "chapter_id", chapterID)
+	editInteractionContent(s, i, fmt.Sprintf("✅ `latest_known_chapter_id` untuk `%s` diatur ke `%s`.", mangaID, chapterID))
+}
@@ -3,9 +3,10 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
@@ -13,35 +14,60 @@ import (
 
 var httpClient = &http.Client{Timeout: 10 * time.Second}
 
-func makeAPIRequest(url string) ([]byte, error) {
+// APIStatusError dikembalikan saat API sumber membalas dengan status selain 200.
+type APIStatusError struct {
+	StatusCode int
+}
+
+func (e *APIStatusError) Error() string {
+	return fmt.Sprintf("API returned non-200 status code: %d", e.StatusCode)
+}
+
+// isNotFound bernilai true jika API sumber membalas 404 (series/chapter dihapus atau dipindah).
+func isNotFound(err error) bool {
+	var statusErr *APIStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// endpoint adalah nama tetap untuk label metrik, bukan URL lengkap
+func makeAPIRequest(endpoint string, url string) ([]byte, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/109.0.0.0 Safari/537.36")
 
+	start := time.Now()
 	resp, err := httpClient.Do(req)
 	if err != nil {
+		observeAPIRequest(endpoint, 0, start)
+		health.recordAPIResult(true)
+		slog.Warn("API request failed", "endpoint", endpoint, "api_url", redactSecrets(url), "duration", time.Since(start), "error", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
+	defer observeAPIRequest(endpoint, resp.StatusCode, start)
+	health.recordAPIResult(resp.StatusCode != http.StatusOK)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned non-200 status code: %d", resp.StatusCode)
+		slog.Warn("API returned non-200 status code", "endpoint", endpoint, "api_url", redactSecrets(url),
+			"status", resp.StatusCode, "duration", time.Since(start))
+		return nil, &APIStatusError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Raw API Response for %s: %s", url, string(body))
+	slog.Debug("Raw API response", "endpoint", endpoint, "api_url", redactSecrets(url),
+		"duration", time.Since(start), "body", truncateForLog(redactSecrets(string(body))))
 	return body, nil
 }
 
 func GetChapterList(mangaID string, page int, pageSize int) (*APIResponseChapter, error) {
 	apiURL := fmt.Sprintf("%s/v1/chapter/%s/list?page=%d&page_size=%d&sort_by=chapter_number&sort_order=desc", cfg.APIBaseURL, mangaID, page, pageSize)
 
-	body, err := makeAPIRequest(apiURL)
+	body, err := makeAPIRequest("chapter_list", apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +85,7 @@ func SearchManga(query string, page int) (*APIResponseManga, error) {
 	encodedQuery := url.QueryEscape(query)
 	apiURL := fmt.Sprintf("%s/v1/manga/list?page=%d&page_size=3&sort=latest&sort_order=desc&q=%s", cfg.APIBaseURL, page, encodedQuery)
 
-	body, err := makeAPIRequest(apiURL)
+	body, err := makeAPIRequest("manga_search", apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +100,7 @@ func SearchManga(query string, page int) (*APIResponseManga, error) {
 func GetLatestChapter(mangaID string) (*Chapter, error) {
 	apiURL := fmt.Sprintf("%s/v1/chapter/%s/list?page=1&page_size=1&sort_by=chapter_number&sort_order=desc", cfg.APIBaseURL, mangaID)
 
-	body, err := makeAPIRequest(apiURL)
+	body, err := makeAPIRequest("latest_chapter", apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +118,7 @@ func GetLatestChapter(mangaID string) (*Chapter, error) {
 func GetMangaDetails(mangaID string) (*Manga, error) {
 	apiURL := fmt.Sprintf("%s/v1/manga/detail/%s", cfg.APIBaseURL, mangaID)
 
-	body, err := makeAPIRequest(apiURL)
+	body, err := makeAPIRequest("manga_detail", apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -104,3 +130,22 @@ func GetMangaDetails(mangaID string) (*Manga, error) {
 
 	return &apiResp.Data, nil
 }
+
+func GetChapterDetails(chapterID string) (*Chapter, error) {
+	apiURL := fmt.Sprintf("%s/v1/chapter/detail/%s", cfg.APIBaseURL, chapterID)
+
+	body, err := makeAPIRequest("chapter_detail", apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResp APIResponseChapterDetail
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, err
+	}
+	// Sebagian sumber membalas 200 dengan data kosong untuk chapter yang tidak ada
+	if apiResp.Data.ID == "" {
+		return nil, &APIStatusError{StatusCode: http.StatusNotFound}
+	}
+	return &apiResp.Data, nil
+}
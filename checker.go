@@ -0,0 +1,310 @@
+// checker.go (Pengecekan chapter baru)
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// checkMutex mencegah dua pengecekan penuh berjalan bersamaan
+	checkMutex   = &sync.Mutex{}
+	checkRunning atomic.Bool
+	// announceMutex membuat pengumuman chapter satu per satu (checker, /admin check, webhook)
+	announceMutex = &sync.Mutex{}
+)
+
+// checkRequest adalah permintaan pengecekan dari /admin check. MangaID kosong berarti semua series;
+// untuk satu series hasilnya dikirim ke Result (buffer 1 agar checker tidak tertahan).
+type checkRequest struct {
+	MangaID        string
+	KnownChapterID string
+	Result         chan checkResult
+}
+
+type checkResult struct {
+	Found bool
+	Err   error
+}
+
+// errCheckInterrupted dicatat untuk run yang terpotong shutdown.
+var errCheckInterrupted = errors.New("interrupted by shutdown")
+
+// allSeriesFailedError dicatat untuk run yang semua series-nya gagal (misalnya API sumber mati).
+type allSeriesFailedError struct {
+	Failures int
+}
+
+func (e *allSeriesFailedError) Error() string {
+	return fmt.Sprintf("all %d series failed", e.Failures)
+}
+
+// checkForUpdates berhenti di antara manga saat ctx dibatalkan, sehingga
+// notifikasi dan updateLatestKnownChapter untuk satu manga tidak terpotong.
+// Tanpa force, hanya manga yang jadwal cek-nya sudah lewat yang diperiksa.
+func checkForUpdates(ctx context.Context, force bool) {
+	if !checkMutex.TryLock() {
+		slog.Debug("Update check already running, skipping")
+		return
+	}
+	defer checkMutex.Unlock()
+	checkRunning.Store(true)
+	defer checkRunning.Store(false)
+
+	start := time.Now()
+	defer func() { updateCheckDuration.Observe(time.Since(start).Seconds()) }()
+
+	var dueBefore time.Time
+	if !force {
+		dueBefore = start
+	}
+	mangaToCheck, queryErr := getUniqueMangaForUpdateCheck(db, dueBefore)
+	if queryErr == nil && len(mangaToCheck) == 0 {
+		// Belum ada series yang jatuh tempo, tidak perlu dicatat sebagai run
+		health.recordUpdateCheck(nil, 0)
+		return
+	}
+
+	run := CheckRun{StartedAt: start}
+	runID, err := StartCheckRun(db, start)
+	if err != nil {
+		slog.Error("Failed to record check run", "error", err)
+	}
+	run.ID = runID
+	defer func() {
+		if run.ID == 0 {
+			return
+		}
+		if err := FinishCheckRun(db, run); err != nil {
+			slog.Error("Failed to finish check run", "run_id", run.ID, "error", err)
+		}
+	}()
+
+	if err := queryErr; err != nil {
+		slog.Error("Error getting unique manga for update check", "error", err)
+		health.recordUpdateCheck(err, 0)
+		run.Error, run.ErrorCategory = err.Error(), errorCategory(err)
+		return
+	}
+
+	for mangaID, knownChapterID := range mangaToCheck {
+		if ctx.Err() != nil {
+			slog.Info("Update check interrupted by shutdown")
+			run.Error, run.ErrorCategory = errCheckInterrupted.Error(), errorCategory(errCheckInterrupted)
+			return
+		}
+		found, err := checkMangaForUpdate(mangaID, knownChapterID)
+		run.SeriesChecked++
+		if err != nil {
+			run.Failures++
+		} else if found {
+			run.NewChapters++
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(3 * time.Second):
+		}
+	}
+	// Run yang semua series-nya gagal (misalnya API sumber mati) bukan run yang sukses
+	if run.SeriesChecked > 0 && run.Failures == run.SeriesChecked {
+		err := &allSeriesFailedError{Failures: run.Failures}
+		health.recordUpdateCheck(err, run.Failures)
+		run.Error, run.ErrorCategory = err.Error(), errorCategory(err)
+		return
+	}
+	health.recordUpdateCheck(nil, run.Failures)
+}
+
+// checkMangaForUpdate mengembalikan true jika chapter baru ditemukan dan notifikasinya terkirim.
+func checkMangaForUpdate(mangaID, knownChapterID string) (found bool, err error) {
+	latestChanged := false
+	defer func() {
+		scheduleNextCheck(mangaID, latestChanged)
+		if err == nil {
+			if clearErr := ClearSeriesFailure(db, mangaID); clearErr != nil {
+				slog.Error("Failed to clear series failure", "manga_id", mangaID, "error", clearErr)
+			}
+			if clearErr := MarkMangaAvailable(db, mangaID); clearErr != nil {
+				slog.Error("Failed to mark manga available", "manga_id", mangaID, "error", clearErr)
+			}
+			return
+		}
+		health.recordSeriesError(mangaID, err)
+		notFoundCount, recordErr := RecordSeriesFailure(db, mangaID, err.Error(), errorCategory(err), isNotFound(err), time.Now())
+		if recordErr != nil {
+			slog.Error("Failed to record series failure", "manga_id", mangaID, "error", recordErr)
+			return
+		}
+		if notFoundCount >= cfg.UnavailableAfter404s {
+			handleUnavailableSeries(mangaID)
+		}
+	}()
+
+	latestChapter, err := GetLatestChapter(mangaID)
+	if err != nil {
+		slog.Warn("Failed to get latest chapter", "manga_id", mangaID, "error", err)
+		return false, err
+	}
+	if latestChapter.ID == knownChapterID {
+		return false, nil
+	}
+	latestChanged = true
+
+	found, stale, err := announceChapter(mangaID, latestChapter)
+	if stale {
+		handleStaleChapter(mangaID, knownChapterID, latestChapter)
+	}
+	return found, err
+}
+
+// announceChapter mengirim notifikasi untuk chapter jika memang rilis baru. Dipakai oleh
+// checker (polling) dan webhook; stale bernilai true jika chapter tidak lebih baru dari
+// chapter yang sudah pernah diumumkan.
+func announceChapter(mangaID string, chapter *Chapter) (found bool, stale bool, err error) {
+	// Polling dan webhook bisa melaporkan chapter yang sama hampir bersamaan
+	announceMutex.Lock()
+	defer announceMutex.Unlock()
+
+	if err := RecordSeenChapter(db, mangaID, chapter, time.Now()); err != nil {
+		slog.Error("Failed to record seen chapter", "manga_id", mangaID, "chapter_id", chapter.ID, "error", err)
+	}
+	newRelease, err := isNewRelease(mangaID, chapter)
+	if err != nil {
+		slog.Error("Failed to compare with seen chapters", "manga_id", mangaID, "error", err)
+		return false, false, err
+	}
+	if !newRelease {
+		return false, true, nil
+	}
+
+	mangaDetails, err := GetMangaDetails(mangaID)
+	if err != nil {
+		slog.Warn("Failed to get manga details", "manga_id", mangaID, "error", err)
+		return false, false, err
+	}
+
+	if err := SetMangaCover(db, mangaID, mangaDetails.CoverURL); err != nil {
+		slog.Error("Failed to save manga cover", "manga_id", mangaID, "error", err)
+	}
+
+	slog.Info("New chapter found", "manga_id", mangaID, "title", mangaDetails.Title, "chapter_id", chapter.ID)
+	chaptersDetected.Inc()
+
+	// Chapter tetap ditandai terumumkan walau tidak ada yang menerima (tidak ada watcher atau semua
+	// saluran watcher nonaktif), agar tidak dideteksi ulang dan tetap muncul di feed, API, dan webhook server
+	watched, err := notifyWatchers(context.Background(), Notification{Kind: NotificationNewChapter, Manga: mangaDetails, Chapter: chapter})
+	if err != nil {
+		return false, false, err
+	}
+	dispatchChapterEvent(mangaDetails, chapter)
+
+	if err := MarkChapterAnnounced(db, mangaID, chapter.ID); err != nil {
+		slog.Error("Failed to mark chapter announced", "manga_id", mangaID, "chapter_id", chapter.ID, "error", err)
+	}
+
+	if err := updateLatestKnownChapter(db, mangaID, chapter.ID); err != nil {
+		slog.Error("Failed to update latest known chapter", "manga_id", mangaID, "error", err)
+	}
+	return watched, false, nil
+}
+
+// isNewRelease membandingkan chapter terbaru dengan chapter tertinggi yang pernah diumumkan.
+// Nomor yang sama dianggap re-upload, kecuali keduanya tanpa nomor dan tanggal rilisnya lebih baru.
+func isNewRelease(mangaID string, latest *Chapter) (bool, error) {
+	highest, found, err := GetHighestAnnouncedChapter(db, mangaID)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		// Belum ada riwayat (misalnya series yang diikuti sebelum ledger ada): batas bawahnya chapter
+		// latest_known_chapter_id. Progres watcher tidak dipakai karena watcher bisa saja sudah
+		// membaca chapter baru ini lebih dulu lewat reader atau tombol Tandai Terbaru.
+		knownChapterID, err := getLatestKnownChapter(db, mangaID)
+		if err == sql.ErrNoRows || (err == nil && knownChapterID == "") {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		known, err := getKnownChapter(mangaID, knownChapterID)
+		if isNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		highest = SeenChapter{ChapterID: known.ID, Number: known.Number, ReleaseDate: known.ReleaseDate}
+	}
+	if latest.Number != highest.Number {
+		return latest.Number > highest.Number, nil
+	}
+	if latest.Number != 0 {
+		return false, nil
+	}
+	latestRelease, err1 := time.Parse(time.RFC3339, latest.ReleaseDate)
+	highestRelease, err2 := time.Parse(time.RFC3339, highest.ReleaseDate)
+	return err1 == nil && err2 == nil && latestRelease.After(highestRelease), nil
+}
+
+// getKnownChapter mencari chapter di ledger, lalu di API untuk chapter yang belum pernah tercatat.
+func getKnownChapter(mangaID, chapterID string) (*Chapter, error) {
+	chapter, err := GetSeenChapter(db, mangaID, chapterID)
+	if err == nil {
+		return chapter, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+	return GetChapterDetails(chapterID)
+}
+
+// handleStaleChapter dipanggil saat chapter terbaru berubah tanpa rilis baru. Hanya dianggap
+// rollback jika chapter yang dikenal sebelumnya hilang dari sumber atau nomor chapter terbaru turun;
+// selain itu (misalnya chapter sudah lebih dulu diumumkan lewat webhook, atau re-upload dengan
+// nomor yang sama) cukup latest_known_chapter_id yang diperbarui.
+func handleStaleChapter(mangaID, knownChapterID string, latest *Chapter) {
+	if knownChapterID != "" {
+		known, err := getKnownChapter(mangaID, knownChapterID)
+		if err != nil && !isNotFound(err) {
+			slog.Warn("Failed to get previously known chapter", "manga_id", mangaID, "chapter_id", knownChapterID, "error", err)
+			return
+		}
+		if isNotFound(err) || latest.Number < known.Number {
+			handleChapterRollback(mangaID, knownChapterID, latest)
+			return
+		}
+	}
+	slog.Debug("Latest chapter already announced", "manga_id", mangaID, "chapter_id", latest.ID)
+	if err := updateLatestKnownChapter(db, mangaID, latest.ID); err != nil {
+		slog.Error("Failed to update latest known chapter", "manga_id", mangaID, "error", err)
+	}
+}
+
+// handleChapterRollback dipanggil saat chapter terbaru turun (chapter terbaru dihapus dari sumber).
+func handleChapterRollback(mangaID, knownChapterID string, latest *Chapter) {
+	slog.Info("Latest chapter changed without a new release, notification suppressed",
+		"manga_id", mangaID, "known_chapter_id", knownChapterID, "chapter_id", latest.ID, "chapter_number", latest.Number)
+
+	if knownChapterID != "" {
+		if _, err := GetChapterDetails(knownChapterID); isNotFound(err) {
+			if err := MarkChapterRemoved(db, mangaID, knownChapterID, time.Now()); err != nil {
+				slog.Error("Failed to mark chapter removed", "manga_id", mangaID, "chapter_id", knownChapterID, "error", err)
+			}
+		}
+	}
+	repaired, err := RepairProgressAfterRollback(db, mangaID, latest)
+	if err != nil {
+		slog.Error("Failed to repair user progress", "manga_id", mangaID, "error", err)
+	} else if repaired > 0 {
+		slog.Info("User progress moved to current latest chapter", "manga_id", mangaID, "users", repaired)
+	}
+	if err := updateLatestKnownChapter(db, mangaID, latest.ID); err != nil {
+		slog.Error("Failed to update latest known chapter", "manga_id", mangaID, "error", err)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTestDB membuka database kosong di direktori sementara dan memasangnya sebagai db global.
+func newTestDB(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	testDB, err := InitDB()
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	previous := db
+	db = testDB
+	t.Cleanup(func() {
+		db = previous
+		testDB.Close()
+	})
+}
+
+func TestIsNewRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		announced []Chapter
+		known     *Chapter  // latest_known_chapter_id, dicatat di ledger tanpa diumumkan
+		progress  []float64 // progres watcher tidak memengaruhi hasil
+		latest    Chapter
+		want      bool
+	}{
+		{
+			name:   "no history and nothing known",
+			latest: Chapter{ID: "c1", Number: 1},
+			want:   true,
+		},
+		{
+			name:   "no history, above known chapter",
+			known:  &Chapter{ID: "c5", Number: 5},
+			latest: Chapter{ID: "c6", Number: 6},
+			want:   true,
+		},
+		{
+			name:     "no history, watcher already read the new chapter",
+			known:    &Chapter{ID: "c5", Number: 5},
+			progress: []float64{5, 6},
+			latest:   Chapter{ID: "c6", Number: 6},
+			want:     true,
+		},
+		{
+			name:   "no history, re-upload of known number",
+			known:  &Chapter{ID: "c5", Number: 5},
+			latest: Chapter{ID: "c5-v2", Number: 5},
+			want:   false,
+		},
+		{
+			name:   "no history, below known chapter",
+			known:  &Chapter{ID: "c5", Number: 5},
+			latest: Chapter{ID: "c4", Number: 4},
+			want:   false,
+		},
+		{
+			name:   "no history, unnumbered and nothing known",
+			latest: Chapter{ID: "oneshot"},
+			want:   true,
+		},
+		{
+			name:      "higher than announced",
+			announced: []Chapter{{ID: "c10", Number: 10}},
+			latest:    Chapter{ID: "c11", Number: 11},
+			want:      true,
+		},
+		{
+			name:      "announced history wins over known chapter",
+			announced: []Chapter{{ID: "c10", Number: 10}},
+			known:     &Chapter{ID: "c5", Number: 5},
+			latest:    Chapter{ID: "c9", Number: 9},
+			want:      false,
+		},
+		{
+			name:      "re-upload of announced number",
+			announced: []Chapter{{ID: "c10", Number: 10}},
+			latest:    Chapter{ID: "c10-v2", Number: 10},
+			want:      false,
+		},
+		{
+			name:      "rollback to lower number",
+			announced: []Chapter{{ID: "c9", Number: 9}, {ID: "c10", Number: 10}},
+			latest:    Chapter{ID: "c9", Number: 9},
+			want:      false,
+		},
+		{
+			name:      "unnumbered with newer release date",
+			announced: []Chapter{{ID: "a", ReleaseDate: "2024-01-01T00:00:00Z"}},
+			latest:    Chapter{ID: "b", ReleaseDate: "2024-02-01T00:00:00Z"},
+			want:      true,
+		},
+		{
+			name:      "unnumbered with same release date",
+			announced: []Chapter{{ID: "a", ReleaseDate: "2024-01-01T00:00:00Z"}},
+			latest:    Chapter{ID: "b", ReleaseDate: "2024-01-01T00:00:00Z"},
+			want:      false,
+		},
+		{
+			name:      "unnumbered without release date",
+			announced: []Chapter{{ID: "a", ReleaseDate: "2024-01-01T00:00:00Z"}},
+			latest:    Chapter{ID: "b"},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			const mangaID = "manga"
+			for _, chapter := range tt.announced {
+				if err := RecordSeenChapter(db, mangaID, &chapter, time.Now()); err != nil {
+					t.Fatal(err)
+				}
+				if err := MarkChapterAnnounced(db, mangaID, chapter.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for idx, progress := range tt.progress {
+				item := WatchlistItem{MangaID: mangaID, UserID: fmt.Sprint("user", idx), MangaTitle: "Manga", UserProgressChapterNumber: progress}
+				if err := AddToWatchlist(db, item); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.known != nil {
+				seedKnownChapter(t, mangaID, tt.known)
+			}
+
+			got, err := isNewRelease(mangaID, &tt.latest)
+			if err != nil {
+				t.Fatalf("isNewRelease: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isNewRelease() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// seedKnownChapter mencatat chapter di ledger (tanpa diumumkan) dan menjadikannya latest_known_chapter_id.
+func seedKnownChapter(t *testing.T, mangaID string, chapter *Chapter) {
+	t.Helper()
+	if err := RecordSeenChapter(db, mangaID, chapter, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO manga_updates (manga_id, latest_known_chapter_id) VALUES (?, ?)
+              ON CONFLICT(manga_id) DO UPDATE SET latest_known_chapter_id = excluded.latest_known_chapter_id`, mangaID, chapter.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandleStaleChapterIgnoresRaces(t *testing.T) {
+	newTestDB(t)
+	const mangaID = "manga"
+	// Webhook sudah mengumumkan chapter 11 selagi polling masih memegang chapter 10
+	item := WatchlistItem{MangaID: mangaID, UserID: "reader", MangaTitle: "Manga", UserProgressChapterID: "c11-old", UserProgressChapterNumber: 11}
+	if err := AddToWatchlist(db, item); err != nil {
+		t.Fatal(err)
+	}
+	seedKnownChapter(t, mangaID, &Chapter{ID: "c10", Number: 10})
+	latest := &Chapter{ID: "c11", Number: 11}
+
+	handleStaleChapter(mangaID, "c10", latest)
+
+	known, err := getLatestKnownChapter(db, mangaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if known != latest.ID {
+		t.Errorf("latest known chapter = %q, want %q", known, latest.ID)
+	}
+	// Bukan rollback, jadi progres tidak boleh dipindah oleh RepairProgressAfterRollback
+	got, err := GetWatchlistItem(db, "reader", mangaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserProgressChapterID != "c11-old" {
+		t.Errorf("progress chapter = %q, want unchanged c11-old", got.UserProgressChapterID)
+	}
+}
+
+func TestRepairProgressAfterRollback(t *testing.T) {
+	newTestDB(t)
+	const mangaID = "manga"
+	latest := &Chapter{ID: "c9", Number: 9}
+	progress := []struct {
+		userID    string
+		chapterID string
+		number    float64
+		want      WatchlistItem
+	}{
+		// Di atas chapter terbaru: dipindah ke chapter terbaru
+		{"ahead", "c10", 10, WatchlistItem{UserProgressChapterID: "c9", UserProgressChapterNumber: 9}},
+		// Nomor sama tapi ID chapter yang sudah dihapus: dipindah ke ID baru
+		{"reupload", "c9-old", 9, WatchlistItem{UserProgressChapterID: "c9", UserProgressChapterNumber: 9}},
+		{"current", "c9", 9, WatchlistItem{UserProgressChapterID: "c9", UserProgressChapterNumber: 9}},
+		{"behind", "c5", 5, WatchlistItem{UserProgressChapterID: "c5", UserProgressChapterNumber: 5}},
+	}
+	for _, p := range progress {
+		item := WatchlistItem{MangaID: mangaID, UserID: p.userID, MangaTitle: "Manga", UserProgressChapterID: p.chapterID, UserProgressChapterNumber: p.number}
+		if err := AddToWatchlist(db, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repaired, err := RepairProgressAfterRollback(db, mangaID, latest)
+	if err != nil {
+		t.Fatalf("RepairProgressAfterRollback: %v", err)
+	}
+	if repaired != 2 {
+		t.Errorf("repaired = %d, want 2", repaired)
+	}
+	for _, p := range progress {
+		item, err := GetWatchlistItem(db, p.userID, mangaID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.UserProgressChapterID != p.want.UserProgressChapterID || item.UserProgressChapterNumber != p.want.UserProgressChapterNumber {
+			t.Errorf("%s: progress = %s/%.1f, want %s/%.1f", p.userID, item.UserProgressChapterID, item.UserProgressChapterNumber,
+				p.want.UserProgressChapterID, p.want.UserProgressChapterNumber)
+		}
+	}
+}
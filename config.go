@@ -2,8 +2,10 @@
 package main
 
 import (
-	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +13,41 @@ type Config struct {
 	UpdateChannelID string
 	APIBaseURL      string
 	ReaderBaseURL   string
+	LogLevel        string // debug, info, warn, error
+	LogFormat       string // json atau text
+	ShutdownTimeout time.Duration
+
+	DevGuildID              string // Jika diisi, command hanya didaftarkan ke guild ini
+	SkipCommandRegistration bool
+
+	AdminRoleID string // Role yang boleh memakai /admin di server mana pun
+	// Server operator; hanya di sini izin Manage Server cukup untuk /admin. Default ke DEV_GUILD_ID
+	OperatorGuildID string
+
+	// Preview link reader butuh intent Message Content yang harus diaktifkan di developer portal
+	UnfurlEnabled bool
+
+	UnavailableAfter404s int // Series ditandai tidak tersedia setelah sekian 404 berturut-turut
+
+	// Series dengan watcher Discord sebanyak ini di-mention lewat role yang dikelola bot; 0 berarti nonaktif
+	SeriesRoleMinWatchers int
+
+	// Batas jadwal polling per series (lihat schedule.go)
+	PollMinInterval time.Duration
+	PollMaxInterval time.Duration
+
+	WebhookSecret string // Secret HMAC untuk /webhooks/chapter; kosong berarti endpoint nonaktif
+
+	PublicBaseURL string // URL publik server HTTP bot, dipakai untuk link feed
+
+	ReaderCallbackSecret string // Secret HMAC untuk callback situs reader; kosong berarti /link nonaktif
+
+	// Notifikasi email; nonaktif jika SMTP_HOST atau SMTP_FROM kosong
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
 }
 
 func LoadConfig() *Config {
@@ -19,10 +56,87 @@ func LoadConfig() *Config {
 		UpdateChannelID: os.Getenv("UPDATE_CHANNEL_ID"),
 		APIBaseURL:      os.Getenv("API_BASE_URL"),
 		ReaderBaseURL:   os.Getenv("READER_BASE_URL"),
+		LogLevel:        os.Getenv("LOG_LEVEL"),
+		LogFormat:       os.Getenv("LOG_FORMAT"),
+		ShutdownTimeout: 30 * time.Second,
+
+		DevGuildID:              os.Getenv("DEV_GUILD_ID"),
+		SkipCommandRegistration: os.Getenv("SKIP_COMMAND_REGISTRATION") == "true",
+
+		AdminRoleID:     os.Getenv("ADMIN_ROLE_ID"),
+		OperatorGuildID: os.Getenv("OPERATOR_GUILD_ID"),
+
+		UnfurlEnabled: os.Getenv("UNFURL_ENABLED") == "true",
+
+		UnavailableAfter404s: 5,
+
+		PollMinInterval: 10 * time.Minute,
+		PollMaxInterval: 24 * time.Hour,
+
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+
+		PublicBaseURL: strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/"),
+
+		ReaderCallbackSecret: os.Getenv("READER_CALLBACK_SECRET"),
+
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     os.Getenv("SMTP_FROM"),
+	}
+	if cfg.OperatorGuildID == "" {
+		cfg.OperatorGuildID = cfg.DevGuildID
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "587"
 	}
 
 	if cfg.BotToken == "" || cfg.UpdateChannelID == "" || cfg.APIBaseURL == "" || cfg.ReaderBaseURL == "" {
-		log.Fatalf("FATAL: One or more required environment variables are not set. Please check BOT_TOKEN, UPDATE_CHANNEL_ID, API_BASE_URL, READER_BASE_URL.")
+		fatal("FATAL: One or more required environment variables are not set. Please check BOT_TOKEN, UPDATE_CHANNEL_ID, API_BASE_URL, READER_BASE_URL.")
+	}
+
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			fatal("FATAL: SHUTDOWN_TIMEOUT must be a duration such as 30s", "value", v)
+		}
+		cfg.ShutdownTimeout = timeout
+	}
+
+	if v := os.Getenv("UNAVAILABLE_AFTER_404S"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			fatal("FATAL: UNAVAILABLE_AFTER_404S must be a positive integer", "value", v)
+		}
+		cfg.UnavailableAfter404s = n
+	}
+
+	if v := os.Getenv("SERIES_ROLE_MIN_WATCHERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			fatal("FATAL: SERIES_ROLE_MIN_WATCHERS must be a non-negative integer", "value", v)
+		}
+		cfg.SeriesRoleMinWatchers = n
+	}
+
+	if v := os.Getenv("POLL_MIN_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			fatal("FATAL: POLL_MIN_INTERVAL must be a duration such as 10m", "value", v)
+		}
+		cfg.PollMinInterval = interval
+	}
+	if v := os.Getenv("POLL_MAX_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			fatal("FATAL: POLL_MAX_INTERVAL must be a duration such as 24h", "value", v)
+		}
+		cfg.PollMaxInterval = interval
+	}
+	if cfg.PollMaxInterval < cfg.PollMinInterval {
+		fatal("FATAL: POLL_MAX_INTERVAL must not be shorter than POLL_MIN_INTERVAL",
+			"min", cfg.PollMinInterval, "max", cfg.PollMaxInterval)
 	}
 
 	return cfg
@@ -3,6 +3,8 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3" // Ganti driver ke SQLite
 )
@@ -27,6 +29,14 @@ func InitDB() (*sql.DB, error) {
 	if _, err = db.Exec(queryWatchlist); err != nil {
 		return nil, err
 	}
+	// Kolom rak (shelf) ditambahkan belakangan, jadi perlu migrasi untuk DB lama
+	if err = addColumnIfNotExists(db, "watchlist", "shelf", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+	// deleted_at diisi saat soft-delete (unix milidetik), NULL untuk baris aktif
+	if err = addColumnIfNotExists(db, "watchlist", "deleted_at", "INTEGER"); err != nil {
+		return nil, err
+	}
 
 	// Buat tabel manga_updates
 	queryMangaUpdates := `
@@ -34,27 +44,310 @@ func InitDB() (*sql.DB, error) {
 		manga_id TEXT PRIMARY KEY,
 		latest_known_chapter_id TEXT
 	);`
-	_, err = db.Exec(queryMangaUpdates)
+	if _, err = db.Exec(queryMangaUpdates); err != nil {
+		return nil, err
+	}
+
+	// Buat tabel guild_settings untuk pengaturan per server
+	queryGuildSettings := `
+	CREATE TABLE IF NOT EXISTS guild_settings (
+		guild_id TEXT PRIMARY KEY,
+		unfurl_links INTEGER NOT NULL DEFAULT 0
+	);`
+	if _, err = db.Exec(queryGuildSettings); err != nil {
+		return nil, err
+	}
+
+	// Riwayat pengecekan update dan kegagalan per series
+	queryCheckRuns := `
+	CREATE TABLE IF NOT EXISTS check_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		started_at INTEGER NOT NULL,
+		finished_at INTEGER,
+		series_checked INTEGER NOT NULL DEFAULT 0,
+		new_chapters INTEGER NOT NULL DEFAULT 0,
+		failures INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE IF NOT EXISTS series_failures (
+		manga_id TEXT PRIMARY KEY,
+		consecutive_failures INTEGER NOT NULL,
+		last_error TEXT NOT NULL,
+		first_failure_at INTEGER NOT NULL,
+		last_failure_at INTEGER NOT NULL
+	);`
+	if _, err = db.Exec(queryCheckRuns); err != nil {
+		return nil, err
+	}
+	// Kategori error (lihat errorCategory) disimpan saat dicatat, selagi error aslinya masih ada
+	if err = addColumnIfNotExists(db, "check_runs", "error_category", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "series_failures", "error_category", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+
+	// Deteksi series yang dihapus/dipindah dari sumber
+	if err = addColumnIfNotExists(db, "series_failures", "consecutive_not_found", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "manga_updates", "unavailable_since", "INTEGER"); err != nil {
+		return nil, err
+	}
+
+	// Ledger chapter yang pernah dilihat checker, untuk mendeteksi rollback/re-upload
+	querySeenChapters := `
+	CREATE TABLE IF NOT EXISTS seen_chapters (
+		manga_id TEXT NOT NULL,
+		chapter_id TEXT NOT NULL,
+		chapter_number REAL NOT NULL,
+		release_date TEXT NOT NULL DEFAULT '',
+		first_seen_at INTEGER NOT NULL,
+		announced INTEGER NOT NULL DEFAULT 0,
+		removed_at INTEGER,
+		PRIMARY KEY (manga_id, chapter_id)
+	);`
+	if _, err = db.Exec(querySeenChapters); err != nil {
+		return nil, err
+	}
+
+	// Jadwal polling adaptif per series (detik unix / detik)
+	for _, column := range []string{"next_check_at", "release_interval", "last_release_at"} {
+		if err = addColumnIfNotExists(db, "manga_updates", column, "INTEGER"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Webhook keluar milik user (owner_type 'user') atau server (owner_type 'guild')
+	queryOutboundWebhooks := `
+	CREATE TABLE IF NOT EXISTS outbound_webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner_type TEXT NOT NULL,
+		owner_id TEXT NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		consecutive_failures INTEGER NOT NULL DEFAULT 0,
+		disabled_until INTEGER,
+		last_error TEXT NOT NULL DEFAULT ''
+	);`
+	if _, err = db.Exec(queryOutboundWebhooks); err != nil {
+		return nil, err
+	}
+
+	// Token rahasia untuk URL feed Atom per user, dan cover untuk entri feed
+	queryFeedTokens := `
+	CREATE TABLE IF NOT EXISTS feed_tokens (
+		user_id TEXT PRIMARY KEY,
+		token TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL
+	);`
+	if _, err = db.Exec(queryFeedTokens); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "manga_updates", "cover_url", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+
+	// Token REST API per user; hanya hash SHA-256 yang disimpan
+	queryAPITokens := `
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		user_id TEXT PRIMARY KEY,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER
+	);`
+	if _, err = db.Exec(queryAPITokens); err != nil {
+		return nil, err
+	}
+
+	// Penautan akun reader: kode sekali pakai dari /link dan akun yang sudah tertaut
+	queryReaderAccounts := `
+	CREATE TABLE IF NOT EXISTS link_codes (
+		code TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL UNIQUE,
+		expires_at INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS reader_accounts (
+		reader_user_id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL UNIQUE,
+		linked_at INTEGER NOT NULL
+	);`
+	if _, err = db.Exec(queryReaderAccounts); err != nil {
+		return nil, err
+	}
+
+	// Pilihan saluran notifikasi per user; user tanpa baris memakai default (Discord saja)
+	queryNotificationPrefs := `
+	CREATE TABLE IF NOT EXISTS notification_prefs (
+		user_id TEXT PRIMARY KEY,
+		discord INTEGER NOT NULL DEFAULT 1,
+		email TEXT NOT NULL DEFAULT '',
+		push_url TEXT NOT NULL DEFAULT ''
+	);`
+	if _, err = db.Exec(queryNotificationPrefs); err != nil {
+		return nil, err
+	}
+
+	// Mode digest: '' (langsung), 'daily' atau 'weekly'; jam/hari mengikuti zona waktu user
+	if err = addColumnIfNotExists(db, "notification_prefs", "digest_mode", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "notification_prefs", "digest_hour", "INTEGER NOT NULL DEFAULT 8"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "notification_prefs", "digest_weekday", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "notification_prefs", "last_digest_at", "INTEGER"); err != nil {
+		return nil, err
+	}
+
+	// Zona waktu IANA ('' berarti default) dan jam tenang (-1 berarti nonaktif)
+	if err = addColumnIfNotExists(db, "notification_prefs", "timezone", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "notification_prefs", "quiet_start", "INTEGER NOT NULL DEFAULT -1"); err != nil {
+		return nil, err
+	}
+	if err = addColumnIfNotExists(db, "notification_prefs", "quiet_end", "INTEGER NOT NULL DEFAULT -1"); err != nil {
+		return nil, err
+	}
+
+	// Chapter yang menunggu dikirim di digest berikutnya atau setelah jam tenang berakhir
+	queryDigestQueue := `
+	CREATE TABLE IF NOT EXISTS digest_queue (
+		user_id TEXT NOT NULL,
+		manga_id TEXT NOT NULL,
+		chapter_id TEXT NOT NULL,
+		chapter_number REAL NOT NULL,
+		queued_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, chapter_id)
+	);`
+	if _, err = db.Exec(queryDigestQueue); err != nil {
+		return nil, err
+	}
+
+	// Role per series yang dikelola bot beserta anggota yang sudah diberi role tersebut
+	querySeriesRoles := `
+	CREATE TABLE IF NOT EXISTS series_roles (
+		manga_id TEXT PRIMARY KEY,
+		guild_id TEXT NOT NULL,
+		role_id TEXT NOT NULL
+	);`
+	if _, err = db.Exec(querySeriesRoles); err != nil {
+		return nil, err
+	}
+	querySeriesRoleMembers := `
+	CREATE TABLE IF NOT EXISTS series_role_members (
+		manga_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		PRIMARY KEY (manga_id, user_id)
+	);`
+	if _, err = db.Exec(querySeriesRoleMembers); err != nil {
+		return nil, err
+	}
+
+	// Pengingat ulang chapter dari tombol "Tunda 1 Hari" di notifikasi
+	querySnoozes := `
+	CREATE TABLE IF NOT EXISTS snoozed_chapters (
+		user_id TEXT NOT NULL,
+		manga_id TEXT NOT NULL,
+		chapter_id TEXT NOT NULL,
+		remind_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, chapter_id)
+	);`
+	if _, err = db.Exec(querySnoozes); err != nil {
+		return nil, err
+	}
+
+	// Alamat email baru menunggu konfirmasi kode sebelum dipakai untuk notifikasi
+	queryEmailVerifications := `
+	CREATE TABLE IF NOT EXISTS email_verifications (
+		user_id TEXT PRIMARY KEY,
+		email TEXT NOT NULL,
+		code TEXT NOT NULL,
+		sent_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0
+	);`
+	if _, err = db.Exec(queryEmailVerifications); err != nil {
+		return nil, err
+	}
+
+	// User yang pernah memakai bot di sebuah server; dipakai untuk membatasi webhook server
+	// ke series yang diikuti member server tersebut
+	queryGuildUsers := `
+	CREATE TABLE IF NOT EXISTS guild_users (
+		guild_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		PRIMARY KEY (guild_id, user_id)
+	);`
+	_, err = db.Exec(queryGuildUsers)
 	return db, err
 }
 
+func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	exists := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if exists {
+		return nil
+	}
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 // GANTI SEMUA PLACEHOLDER DARI $1, $2, DST. KEMBALI MENJADI ?
 func AddToWatchlist(db *sql.DB, item WatchlistItem) error {
+	defer observeDBQuery("add_to_watchlist")()
+	// Baris yang masih menunggu purge diganti dengan yang baru
+	_, err := db.Exec(`DELETE FROM watchlist WHERE manga_id = ? AND user_id = ? AND deleted_at IS NOT NULL`, item.MangaID, item.UserID)
+	if err != nil {
+		return err
+	}
+
 	query := `INSERT OR IGNORE INTO watchlist (manga_id, user_id, manga_title, user_progress_chapter_id, user_progress_chapter_number) 
               VALUES (?, ?, ?, ?, ?)`
-	_, err := db.Exec(query, item.MangaID, item.UserID, item.MangaTitle, item.UserProgressChapterID, item.UserProgressChapterNumber)
+	_, err = db.Exec(query, item.MangaID, item.UserID, item.MangaTitle, item.UserProgressChapterID, item.UserProgressChapterNumber)
 	if err != nil {
 		return err
 	}
 
-	updateQuery := `INSERT OR REPLACE INTO manga_updates (manga_id, latest_known_chapter_id) VALUES (?, ?)`
+	// Upsert agar jadwal polling dan status series yang sudah tersimpan tidak ikut terhapus
+	updateQuery := `INSERT INTO manga_updates (manga_id, latest_known_chapter_id) VALUES (?, ?)
+              ON CONFLICT(manga_id) DO UPDATE SET latest_known_chapter_id = excluded.latest_known_chapter_id`
 	_, err = db.Exec(updateQuery, item.MangaID, item.UserProgressChapterID)
 	return err
 }
 
-func getUniqueMangaForUpdateCheck(db *sql.DB) (map[string]string, error) {
+// getUniqueMangaForUpdateCheck mengembalikan manga yang jadwal cek-nya sudah lewat dueBefore.
+// dueBefore kosong (zero) berarti semua manga.
+func getUniqueMangaForUpdateCheck(db *sql.DB, dueBefore time.Time) (map[string]string, error) {
+	defer observeDBQuery("get_unique_manga_for_update_check")()
 	query := `SELECT manga_id, latest_known_chapter_id FROM manga_updates`
-	rows, err := db.Query(query)
+	var args []any
+	if !dueBefore.IsZero() {
+		query += ` WHERE next_check_at IS NULL OR next_check_at <= ?`
+		args = append(args, dueBefore.Unix())
+	}
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +365,8 @@ func getUniqueMangaForUpdateCheck(db *sql.DB) (map[string]string, error) {
 }
 
 func getUsersForManga(db *sql.DB, mangaID string) ([]string, error) {
-	query := `SELECT user_id FROM watchlist WHERE manga_id = ?`
+	defer observeDBQuery("get_users_for_manga")()
+	query := `SELECT user_id FROM watchlist WHERE manga_id = ? AND deleted_at IS NULL`
 	rows, err := db.Query(query, mangaID)
 	if err != nil {
 		return nil, err
@@ -90,26 +384,29 @@ func getUsersForManga(db *sql.DB, mangaID string) ([]string, error) {
 }
 
 func updateLatestKnownChapter(db *sql.DB, mangaID, newChapterID string) error {
+	defer observeDBQuery("update_latest_known_chapter")()
 	query := `UPDATE manga_updates SET latest_known_chapter_id = ? WHERE manga_id = ?`
 	_, err := db.Exec(query, newChapterID, mangaID)
 	return err
 }
 
 func UpdateUserProgress(db *sql.DB, userID, mangaID, chapterID string, chapterNumber float64) error {
-	query := `UPDATE watchlist SET user_progress_chapter_id = ?, user_progress_chapter_number = ? WHERE user_id = ? AND manga_id = ?`
+	defer observeDBQuery("update_user_progress")()
+	query := `UPDATE watchlist SET user_progress_chapter_id = ?, user_progress_chapter_number = ? WHERE user_id = ? AND manga_id = ? AND deleted_at IS NULL`
 	_, err := db.Exec(query, chapterID, chapterNumber, userID, mangaID)
 	return err
 }
 
 func GetWatchlistForUserPaginated(db *sql.DB, userID string, page int, pageSize int) ([]WatchlistItem, int, error) {
+	defer observeDBQuery("get_watchlist_for_user_paginated")()
 	var totalItems int
-	countQuery := `SELECT COUNT(*) FROM watchlist WHERE user_id = ?`
+	countQuery := `SELECT COUNT(*) FROM watchlist WHERE user_id = ? AND deleted_at IS NULL`
 	err := db.QueryRow(countQuery, userID).Scan(&totalItems)
 	if err != nil {
 		return nil, 0, err
 	}
 	offset := (page - 1) * pageSize
-	query := `SELECT manga_id, user_id, manga_title, user_progress_chapter_id, user_progress_chapter_number FROM watchlist WHERE user_id = ? ORDER BY manga_title ASC LIMIT ? OFFSET ?`
+	query := `SELECT manga_id, user_id, manga_title, user_progress_chapter_id, user_progress_chapter_number, shelf FROM watchlist WHERE user_id = ? AND deleted_at IS NULL ORDER BY manga_title ASC LIMIT ? OFFSET ?`
 	rows, err := db.Query(query, userID, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
@@ -118,7 +415,7 @@ func GetWatchlistForUserPaginated(db *sql.DB, userID string, page int, pageSize
 	var items []WatchlistItem
 	for rows.Next() {
 		var item WatchlistItem
-		if err := rows.Scan(&item.MangaID, &item.UserID, &item.MangaTitle, &item.UserProgressChapterID, &item.UserProgressChapterNumber); err != nil {
+		if err := rows.Scan(&item.MangaID, &item.UserID, &item.MangaTitle, &item.UserProgressChapterID, &item.UserProgressChapterNumber, &item.Shelf); err != nil {
 			return nil, 0, err
 		}
 		items = append(items, item)
@@ -127,17 +424,1037 @@ func GetWatchlistForUserPaginated(db *sql.DB, userID string, page int, pageSize
 }
 
 func DeleteFromWatchlist(db *sql.DB, mangaID string, userID string) error {
+	defer observeDBQuery("delete_from_watchlist")()
 	query := `DELETE FROM watchlist WHERE manga_id = ? AND user_id = ?`
 	_, err := db.Exec(query, mangaID, userID)
 	return err
 }
 
+func SoftDeleteFromWatchlist(db *sql.DB, mangaID, userID string, deletedAt int64) error {
+	defer observeDBQuery("soft_delete_from_watchlist")()
+	query := `UPDATE watchlist SET deleted_at = ? WHERE manga_id = ? AND user_id = ? AND deleted_at IS NULL`
+	_, err := db.Exec(query, deletedAt, mangaID, userID)
+	return err
+}
+
+func RestoreDeletedWatchlist(db *sql.DB, userID string, deletedAt int64) (int64, error) {
+	defer observeDBQuery("restore_deleted_watchlist")()
+	query := `UPDATE watchlist SET deleted_at = NULL WHERE user_id = ? AND deleted_at = ?`
+	res, err := db.Exec(query, userID, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func PurgeDeletedWatchlist(db *sql.DB, before int64) (int64, error) {
+	defer observeDBQuery("purge_deleted_watchlist")()
+	query := `DELETE FROM watchlist WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	res, err := db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func GetWatchlistItem(db *sql.DB, userID, mangaID string) (*WatchlistItem, error) {
+	defer observeDBQuery("get_watchlist_item")()
 	var item WatchlistItem
-	query := `SELECT manga_id, user_id, manga_title, user_progress_chapter_id, user_progress_chapter_number FROM watchlist WHERE user_id = ? AND manga_id = ?`
-	err := db.QueryRow(query, userID, mangaID).Scan(&item.MangaID, &item.UserID, &item.MangaTitle, &item.UserProgressChapterID, &item.UserProgressChapterNumber)
+	query := `SELECT manga_id, user_id, manga_title, user_progress_chapter_id, user_progress_chapter_number, shelf FROM watchlist WHERE user_id = ? AND manga_id = ? AND deleted_at IS NULL`
+	err := db.QueryRow(query, userID, mangaID).Scan(&item.MangaID, &item.UserID, &item.MangaTitle, &item.UserProgressChapterID, &item.UserProgressChapterNumber, &item.Shelf)
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
-}
\ No newline at end of file
+}
+func GetWatchlistForUser(db *sql.DB, userID string) ([]WatchlistItem, error) {
+	defer observeDBQuery("get_watchlist_for_user")()
+	query := `SELECT manga_id, user_id, manga_title, user_progress_chapter_id, user_progress_chapter_number, shelf FROM watchlist WHERE user_id = ? AND deleted_at IS NULL ORDER BY manga_title ASC`
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WatchlistItem
+	for rows.Next() {
+		var item WatchlistItem
+		if err := rows.Scan(&item.MangaID, &item.UserID, &item.MangaTitle, &item.UserProgressChapterID, &item.UserProgressChapterNumber, &item.Shelf); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func IsLinkUnfurlEnabled(db *sql.DB, guildID string) (bool, error) {
+	defer observeDBQuery("is_link_unfurl_enabled")()
+	var enabled bool
+	query := `SELECT unfurl_links FROM guild_settings WHERE guild_id = ?`
+	err := db.QueryRow(query, guildID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return enabled, err
+}
+
+func SetLinkUnfurlEnabled(db *sql.DB, guildID string, enabled bool) error {
+	defer observeDBQuery("set_link_unfurl_enabled")()
+	query := `INSERT INTO guild_settings (guild_id, unfurl_links) VALUES (?, ?)
+              ON CONFLICT(guild_id) DO UPDATE SET unfurl_links = excluded.unfurl_links`
+	_, err := db.Exec(query, guildID, enabled)
+	return err
+}
+
+func BulkDeleteFromWatchlist(db *sql.DB, userID string, mangaIDs []string, deletedAt int64) (int64, error) {
+	defer observeDBQuery("bulk_delete_from_watchlist")()
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var deleted int64
+	for _, mangaID := range mangaIDs {
+		res, err := tx.Exec(`UPDATE watchlist SET deleted_at = ? WHERE manga_id = ? AND user_id = ? AND deleted_at IS NULL`, deletedAt, mangaID, userID)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	return deleted, tx.Commit()
+}
+
+func BulkUpdateUserProgress(db *sql.DB, userID string, chapters map[string]*Chapter) error {
+	defer observeDBQuery("bulk_update_user_progress")()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE watchlist SET user_progress_chapter_id = ?, user_progress_chapter_number = ? WHERE user_id = ? AND manga_id = ? AND deleted_at IS NULL`
+	for mangaID, chapter := range chapters {
+		if _, err := tx.Exec(query, chapter.ID, chapter.Number, userID, mangaID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func BulkSetShelf(db *sql.DB, userID string, mangaIDs []string, shelf string) error {
+	defer observeDBQuery("bulk_set_shelf")()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, mangaID := range mangaIDs {
+		if _, err := tx.Exec(`UPDATE watchlist SET shelf = ? WHERE manga_id = ? AND user_id = ? AND deleted_at IS NULL`, shelf, mangaID, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type MangaWatchCount struct {
+	MangaID    string
+	MangaTitle string
+	Watchers   int
+}
+
+func GetMostWatchedManga(db *sql.DB, limit int) ([]MangaWatchCount, error) {
+	defer observeDBQuery("get_most_watched_manga")()
+	query := `SELECT manga_id, MAX(manga_title), COUNT(*) AS watchers FROM watchlist WHERE deleted_at IS NULL
+              GROUP BY manga_id ORDER BY watchers DESC, MAX(manga_title) ASC LIMIT ?`
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var counts []MangaWatchCount
+	for rows.Next() {
+		var c MangaWatchCount
+		if err := rows.Scan(&c.MangaID, &c.MangaTitle, &c.Watchers); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, nil
+}
+
+func getLatestKnownChapter(db *sql.DB, mangaID string) (string, error) {
+	defer observeDBQuery("get_latest_known_chapter")()
+	var chapterID sql.NullString
+	query := `SELECT latest_known_chapter_id FROM manga_updates WHERE manga_id = ?`
+	err := db.QueryRow(query, mangaID).Scan(&chapterID)
+	return chapterID.String, err
+}
+
+// Jumlah riwayat check_runs yang disimpan
+const maxCheckRuns = 1000
+
+type CheckRun struct {
+	ID            int64      `json:"id"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	SeriesChecked int        `json:"series_checked"`
+	NewChapters   int        `json:"new_chapters"`
+	Failures      int        `json:"failures"`
+	Error         string     `json:"error,omitempty"`
+	ErrorCategory string     `json:"-"`
+}
+
+type SeriesFailure struct {
+	MangaID             string    `json:"manga_id"`
+	MangaTitle          string    `json:"title"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error"`
+	ErrorCategory       string    `json:"error_category"`
+	FirstFailureAt      time.Time `json:"first_failure_at"`
+	LastFailureAt       time.Time `json:"last_failure_at"`
+}
+
+func StartCheckRun(db *sql.DB, startedAt time.Time) (int64, error) {
+	defer observeDBQuery("start_check_run")()
+	res, err := db.Exec(`INSERT INTO check_runs (started_at) VALUES (?)`, startedAt.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func FinishCheckRun(db *sql.DB, run CheckRun) error {
+	defer observeDBQuery("finish_check_run")()
+	query := `UPDATE check_runs SET finished_at = ?, series_checked = ?, new_chapters = ?, failures = ?, error = ?, error_category = ? WHERE id = ?`
+	_, err := db.Exec(query, time.Now().Unix(), run.SeriesChecked, run.NewChapters, run.Failures, run.Error, run.ErrorCategory, run.ID)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM check_runs WHERE id <= ?`, run.ID-maxCheckRuns)
+	return err
+}
+
+func GetRecentCheckRuns(db *sql.DB, limit int) ([]CheckRun, error) {
+	defer observeDBQuery("get_recent_check_runs")()
+	query := `SELECT id, started_at, finished_at, series_checked, new_chapters, failures, error, error_category FROM check_runs ORDER BY id DESC LIMIT ?`
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []CheckRun
+	for rows.Next() {
+		var run CheckRun
+		var startedAt int64
+		var finishedAt sql.NullInt64
+		if err := rows.Scan(&run.ID, &startedAt, &finishedAt, &run.SeriesChecked, &run.NewChapters, &run.Failures, &run.Error, &run.ErrorCategory); err != nil {
+			return nil, err
+		}
+		run.StartedAt = time.Unix(startedAt, 0)
+		if finishedAt.Valid {
+			t := time.Unix(finishedAt.Int64, 0)
+			run.FinishedAt = &t
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// RecordSeriesFailure menaikkan jumlah kegagalan berturut-turut dan mengembalikan
+// jumlah 404 berturut-turut terbaru (direset oleh kegagalan selain 404).
+func RecordSeriesFailure(db *sql.DB, mangaID, errMsg, category string, notFound bool, at time.Time) (int, error) {
+	defer observeDBQuery("record_series_failure")()
+	notFoundCount := 0
+	if notFound {
+		notFoundCount = 1
+	}
+	query := `INSERT INTO series_failures (manga_id, consecutive_failures, consecutive_not_found, last_error, error_category, first_failure_at, last_failure_at)
+              VALUES (?, 1, ?, ?, ?, ?, ?)
+              ON CONFLICT(manga_id) DO UPDATE SET consecutive_failures = consecutive_failures + 1,
+              consecutive_not_found = CASE WHEN excluded.consecutive_not_found = 1 THEN consecutive_not_found + 1 ELSE 0 END,
+              last_error = excluded.last_error, error_category = excluded.error_category, last_failure_at = excluded.last_failure_at`
+	if _, err := db.Exec(query, mangaID, notFoundCount, errMsg, category, at.Unix(), at.Unix()); err != nil {
+		return 0, err
+	}
+	var count int
+	err := db.QueryRow(`SELECT consecutive_not_found FROM series_failures WHERE manga_id = ?`, mangaID).Scan(&count)
+	return count, err
+}
+
+func ClearSeriesFailure(db *sql.DB, mangaID string) error {
+	defer observeDBQuery("clear_series_failure")()
+	_, err := db.Exec(`DELETE FROM series_failures WHERE manga_id = ?`, mangaID)
+	return err
+}
+
+func GetFailingSeries(db *sql.DB, limit int) ([]SeriesFailure, error) {
+	defer observeDBQuery("get_failing_series")()
+	query := `SELECT f.manga_id, COALESCE((SELECT MAX(w.manga_title) FROM watchlist w WHERE w.manga_id = f.manga_id), ''),
+              f.consecutive_failures, f.last_error, f.error_category, f.first_failure_at, f.last_failure_at
+              FROM series_failures f ORDER BY f.consecutive_failures DESC, f.last_failure_at DESC LIMIT ?`
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var failures []SeriesFailure
+	for rows.Next() {
+		var f SeriesFailure
+		var firstAt, lastAt int64
+		if err := rows.Scan(&f.MangaID, &f.MangaTitle, &f.ConsecutiveFailures, &f.LastError, &f.ErrorCategory, &firstAt, &lastAt); err != nil {
+			return nil, err
+		}
+		f.FirstFailureAt = time.Unix(firstAt, 0)
+		f.LastFailureAt = time.Unix(lastAt, 0)
+		failures = append(failures, f)
+	}
+	return failures, nil
+}
+
+// MarkMangaUnavailable mengembalikan true hanya jika status berubah (agar watcher dinotifikasi sekali).
+func MarkMangaUnavailable(db *sql.DB, mangaID string, at time.Time) (bool, error) {
+	defer observeDBQuery("mark_manga_unavailable")()
+	query := `UPDATE manga_updates SET unavailable_since = ? WHERE manga_id = ? AND unavailable_since IS NULL`
+	res, err := db.Exec(query, at.Unix(), mangaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func MarkMangaAvailable(db *sql.DB, mangaID string) error {
+	defer observeDBQuery("mark_manga_available")()
+	_, err := db.Exec(`UPDATE manga_updates SET unavailable_since = NULL WHERE manga_id = ? AND unavailable_since IS NOT NULL`, mangaID)
+	return err
+}
+
+func IsMangaUnavailable(db *sql.DB, mangaID string) (bool, error) {
+	defer observeDBQuery("is_manga_unavailable")()
+	var since sql.NullInt64
+	err := db.QueryRow(`SELECT unavailable_since FROM manga_updates WHERE manga_id = ?`, mangaID).Scan(&since)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return since.Valid, err
+}
+
+func getMangaTitle(db *sql.DB, mangaID string) (string, error) {
+	defer observeDBQuery("get_manga_title")()
+	var title sql.NullString
+	err := db.QueryRow(`SELECT MAX(manga_title) FROM watchlist WHERE manga_id = ?`, mangaID).Scan(&title)
+	return title.String, err
+}
+
+// SeenChapter adalah entri ledger chapter yang pernah dilihat checker.
+type SeenChapter struct {
+	ChapterID   string
+	Number      float64
+	ReleaseDate string
+}
+
+// RecordSeenChapter mencatat chapter ke ledger; chapter yang sudah ada tidak diubah.
+func RecordSeenChapter(db *sql.DB, mangaID string, chapter *Chapter, at time.Time) error {
+	defer observeDBQuery("record_seen_chapter")()
+	query := `INSERT OR IGNORE INTO seen_chapters (manga_id, chapter_id, chapter_number, release_date, first_seen_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := db.Exec(query, mangaID, chapter.ID, chapter.Number, chapter.ReleaseDate, at.Unix())
+	return err
+}
+
+func MarkChapterAnnounced(db *sql.DB, mangaID, chapterID string) error {
+	defer observeDBQuery("mark_chapter_announced")()
+	_, err := db.Exec(`UPDATE seen_chapters SET announced = 1 WHERE manga_id = ? AND chapter_id = ?`, mangaID, chapterID)
+	return err
+}
+
+// MarkChapterRemoved menandai chapter yang sudah tidak ada di sumber.
+func MarkChapterRemoved(db *sql.DB, mangaID, chapterID string, at time.Time) error {
+	defer observeDBQuery("mark_chapter_removed")()
+	query := `UPDATE seen_chapters SET removed_at = ? WHERE manga_id = ? AND chapter_id = ? AND removed_at IS NULL`
+	_, err := db.Exec(query, at.Unix(), mangaID, chapterID)
+	return err
+}
+
+// GetHighestAnnouncedChapter mengembalikan chapter bernomor tertinggi yang pernah diumumkan.
+// found bernilai false jika belum ada chapter yang diumumkan untuk manga ini.
+func GetHighestAnnouncedChapter(db *sql.DB, mangaID string) (chapter SeenChapter, found bool, err error) {
+	defer observeDBQuery("get_highest_announced_chapter")()
+	query := `SELECT chapter_id, chapter_number, release_date FROM seen_chapters
+              WHERE manga_id = ? AND announced = 1 ORDER BY chapter_number DESC, release_date DESC LIMIT 1`
+	err = db.QueryRow(query, mangaID).Scan(&chapter.ChapterID, &chapter.Number, &chapter.ReleaseDate)
+	if err == sql.ErrNoRows {
+		return chapter, false, nil
+	}
+	return chapter, err == nil, err
+}
+
+// RepairProgressAfterRollback memindahkan progres yang menunjuk ke chapter di atas (atau sama
+// dengan, tapi ID berbeda) chapter terbaru ke chapter terbaru tersebut.
+func RepairProgressAfterRollback(db *sql.DB, mangaID string, latest *Chapter) (int64, error) {
+	defer observeDBQuery("repair_progress_after_rollback")()
+	query := `UPDATE watchlist SET user_progress_chapter_id = ?, user_progress_chapter_number = ?
+              WHERE manga_id = ? AND user_progress_chapter_number >= ? AND user_progress_chapter_id != ?`
+	res, err := db.Exec(query, latest.ID, latest.Number, mangaID, latest.Number, latest.ID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MangaSchedule menyimpan ritme rilis yang teramati untuk penjadwalan polling.
+type MangaSchedule struct {
+	NextCheckAt     time.Time
+	ReleaseInterval time.Duration // 0 jika belum diketahui
+	LastReleaseAt   time.Time
+	Watchers        int
+	Unavailable     bool
+}
+
+func GetMangaSchedule(db *sql.DB, mangaID string) (MangaSchedule, error) {
+	defer observeDBQuery("get_manga_schedule")()
+	var schedule MangaSchedule
+	var nextCheckAt, releaseInterval, lastReleaseAt, unavailableSince sql.NullInt64
+	query := `SELECT next_check_at, release_interval, last_release_at, unavailable_since,
+              (SELECT COUNT(*) FROM watchlist w WHERE w.manga_id = m.manga_id AND w.deleted_at IS NULL)
+              FROM manga_updates m WHERE m.manga_id = ?`
+	err := db.QueryRow(query, mangaID).Scan(&nextCheckAt, &releaseInterval, &lastReleaseAt, &unavailableSince, &schedule.Watchers)
+	if err != nil {
+		return schedule, err
+	}
+	if nextCheckAt.Valid {
+		schedule.NextCheckAt = time.Unix(nextCheckAt.Int64, 0)
+	}
+	schedule.ReleaseInterval = time.Duration(releaseInterval.Int64) * time.Second
+	if lastReleaseAt.Valid {
+		schedule.LastReleaseAt = time.Unix(lastReleaseAt.Int64, 0)
+	}
+	schedule.Unavailable = unavailableSince.Valid
+	return schedule, nil
+}
+
+func SetMangaCadence(db *sql.DB, mangaID string, releaseInterval time.Duration, lastReleaseAt time.Time) error {
+	defer observeDBQuery("set_manga_cadence")()
+	query := `UPDATE manga_updates SET release_interval = ?, last_release_at = ? WHERE manga_id = ?`
+	_, err := db.Exec(query, int64(releaseInterval/time.Second), lastReleaseAt.Unix(), mangaID)
+	return err
+}
+
+func SetNextCheck(db *sql.DB, mangaID string, at time.Time) error {
+	defer observeDBQuery("set_next_check")()
+	_, err := db.Exec(`UPDATE manga_updates SET next_check_at = ? WHERE manga_id = ?`, at.Unix(), mangaID)
+	return err
+}
+
+const (
+	webhookOwnerUser  = "user"
+	webhookOwnerGuild = "guild"
+)
+
+type OutboundWebhook struct {
+	ID                  int64
+	OwnerType           string
+	OwnerID             string
+	URL                 string
+	Secret              string
+	ConsecutiveFailures int
+	DisabledUntil       time.Time // zero jika circuit tertutup
+	LastError           string
+}
+
+const outboundWebhookColumns = `id, owner_type, owner_id, url, secret, consecutive_failures, disabled_until, last_error`
+
+func scanOutboundWebhooks(rows *sql.Rows) ([]OutboundWebhook, error) {
+	defer rows.Close()
+	var hooks []OutboundWebhook
+	for rows.Next() {
+		var hook OutboundWebhook
+		var disabledUntil sql.NullInt64
+		if err := rows.Scan(&hook.ID, &hook.OwnerType, &hook.OwnerID, &hook.URL, &hook.Secret,
+			&hook.ConsecutiveFailures, &disabledUntil, &hook.LastError); err != nil {
+			return nil, err
+		}
+		if disabledUntil.Valid {
+			hook.DisabledUntil = time.Unix(disabledUntil.Int64, 0)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+func AddOutboundWebhook(db *sql.DB, ownerType, ownerID, url, secret, createdBy string) (int64, error) {
+	defer observeDBQuery("add_outbound_webhook")()
+	query := `INSERT INTO outbound_webhooks (owner_type, owner_id, url, secret, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := db.Exec(query, ownerType, ownerID, url, secret, createdBy, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func GetOutboundWebhooks(db *sql.DB, ownerType, ownerID string) ([]OutboundWebhook, error) {
+	defer observeDBQuery("get_outbound_webhooks")()
+	query := `SELECT ` + outboundWebhookColumns + ` FROM outbound_webhooks WHERE owner_type = ? AND owner_id = ? ORDER BY id`
+	rows, err := db.Query(query, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboundWebhooks(rows)
+}
+
+// GetOutboundWebhooksForManga mengembalikan webhook milik watcher manga ini dan webhook server
+// yang salah satu member-nya (lihat guild_users) mengikuti manga ini.
+func GetOutboundWebhooksForManga(db *sql.DB, mangaID string) ([]OutboundWebhook, error) {
+	defer observeDBQuery("get_outbound_webhooks_for_manga")()
+	query := `SELECT ` + outboundWebhookColumns + ` FROM outbound_webhooks
+              WHERE (owner_type = 'user' AND owner_id IN (SELECT user_id FROM watchlist WHERE manga_id = ? AND deleted_at IS NULL))
+                 OR (owner_type = 'guild' AND owner_id IN (SELECT g.guild_id FROM guild_users g JOIN watchlist w ON w.user_id = g.user_id
+                                                           WHERE w.manga_id = ? AND w.deleted_at IS NULL))`
+	rows, err := db.Query(query, mangaID, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboundWebhooks(rows)
+}
+
+func RecordGuildUser(db *sql.DB, guildID, userID string) error {
+	defer observeDBQuery("record_guild_user")()
+	_, err := db.Exec(`INSERT OR IGNORE INTO guild_users (guild_id, user_id) VALUES (?, ?)`, guildID, userID)
+	return err
+}
+
+func DeleteOutboundWebhook(db *sql.DB, id int64, ownerType, ownerID string) (bool, error) {
+	defer observeDBQuery("delete_outbound_webhook")()
+	res, err := db.Exec(`DELETE FROM outbound_webhooks WHERE id = ? AND owner_type = ? AND owner_id = ?`, id, ownerType, ownerID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func RecordWebhookSuccess(db *sql.DB, id int64) error {
+	defer observeDBQuery("record_webhook_success")()
+	query := `UPDATE outbound_webhooks SET consecutive_failures = 0, disabled_until = NULL, last_error = '' WHERE id = ?`
+	_, err := db.Exec(query, id)
+	return err
+}
+
+// RecordWebhookFailure menaikkan hitungan gagal dan mengembalikan nilainya yang baru.
+func RecordWebhookFailure(db *sql.DB, id int64, errMsg string) (int, error) {
+	defer observeDBQuery("record_webhook_failure")()
+	query := `UPDATE outbound_webhooks SET consecutive_failures = consecutive_failures + 1, last_error = ? WHERE id = ?`
+	if _, err := db.Exec(query, errMsg, id); err != nil {
+		return 0, err
+	}
+	var failures int
+	err := db.QueryRow(`SELECT consecutive_failures FROM outbound_webhooks WHERE id = ?`, id).Scan(&failures)
+	return failures, err
+}
+
+func SetWebhookDisabledUntil(db *sql.DB, id int64, until time.Time) error {
+	defer observeDBQuery("set_webhook_disabled_until")()
+	_, err := db.Exec(`UPDATE outbound_webhooks SET disabled_until = ? WHERE id = ?`, until.Unix(), id)
+	return err
+}
+
+// GetOrCreateFeedToken mengembalikan token feed user; rotate=true selalu membuat token baru.
+func GetOrCreateFeedToken(db *sql.DB, userID string, rotate bool) (string, error) {
+	defer observeDBQuery("get_or_create_feed_token")()
+	if !rotate {
+		var token string
+		err := db.QueryRow(`SELECT token FROM feed_tokens WHERE user_id = ?`, userID).Scan(&token)
+		if err != sql.ErrNoRows {
+			return token, err
+		}
+	}
+	token, err := generateSecret()
+	if err != nil {
+		return "", err
+	}
+	query := `INSERT INTO feed_tokens (user_id, token, created_at) VALUES (?, ?, ?)
+              ON CONFLICT(user_id) DO UPDATE SET token = excluded.token, created_at = excluded.created_at`
+	_, err = db.Exec(query, userID, token, time.Now().Unix())
+	return token, err
+}
+
+func GetUserByFeedToken(db *sql.DB, token string) (string, error) {
+	defer observeDBQuery("get_user_by_feed_token")()
+	var userID string
+	err := db.QueryRow(`SELECT user_id FROM feed_tokens WHERE token = ?`, token).Scan(&userID)
+	return userID, err
+}
+
+func SetMangaCover(db *sql.DB, mangaID, coverURL string) error {
+	defer observeDBQuery("set_manga_cover")()
+	_, err := db.Exec(`UPDATE manga_updates SET cover_url = ? WHERE manga_id = ?`, coverURL, mangaID)
+	return err
+}
+
+// FeedEntry adalah chapter yang sudah diumumkan untuk series di watchlist user.
+type FeedEntry struct {
+	MangaID       string
+	MangaTitle    string
+	CoverURL      string
+	ChapterID     string
+	ChapterNumber float64
+	ReleaseDate   string
+	SeenAt        time.Time
+}
+
+func GetFeedEntriesForUser(db *sql.DB, userID string, limit int) ([]FeedEntry, error) {
+	defer observeDBQuery("get_feed_entries_for_user")()
+	query := `SELECT s.manga_id, w.manga_title, COALESCE(m.cover_url, ''), s.chapter_id, s.chapter_number, s.release_date, s.first_seen_at
+              FROM seen_chapters s
+              JOIN watchlist w ON w.manga_id = s.manga_id AND w.user_id = ? AND w.deleted_at IS NULL
+              LEFT JOIN manga_updates m ON m.manga_id = s.manga_id
+              WHERE s.announced = 1 AND s.removed_at IS NULL
+              ORDER BY s.first_seen_at DESC, s.chapter_number DESC LIMIT ?`
+	rows, err := db.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []FeedEntry
+	for rows.Next() {
+		var entry FeedEntry
+		var seenAt int64
+		if err := rows.Scan(&entry.MangaID, &entry.MangaTitle, &entry.CoverURL, &entry.ChapterID,
+			&entry.ChapterNumber, &entry.ReleaseDate, &seenAt); err != nil {
+			return nil, err
+		}
+		entry.SeenAt = time.Unix(seenAt, 0)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// SetAPIToken menyimpan hash token baru untuk user, menggantikan token lama.
+func SetAPIToken(db *sql.DB, userID, tokenHash string) error {
+	defer observeDBQuery("set_api_token")()
+	query := `INSERT INTO api_tokens (user_id, token_hash, created_at) VALUES (?, ?, ?)
+              ON CONFLICT(user_id) DO UPDATE SET token_hash = excluded.token_hash, created_at = excluded.created_at, last_used_at = NULL`
+	_, err := db.Exec(query, userID, tokenHash, time.Now().Unix())
+	return err
+}
+
+func RevokeAPIToken(db *sql.DB, userID string) (bool, error) {
+	defer observeDBQuery("revoke_api_token")()
+	res, err := db.Exec(`DELETE FROM api_tokens WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetUserByAPIToken mencari pemilik token dan mencatat waktu pemakaian terakhir.
+func GetUserByAPIToken(db *sql.DB, tokenHash string) (string, error) {
+	defer observeDBQuery("get_user_by_api_token")()
+	var userID string
+	if err := db.QueryRow(`SELECT user_id FROM api_tokens WHERE token_hash = ?`, tokenHash).Scan(&userID); err != nil {
+		return "", err
+	}
+	_, err := db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE user_id = ?`, time.Now().Unix(), userID)
+	return userID, err
+}
+
+// SetLinkCode menyimpan kode penautan baru untuk user, menggantikan kode sebelumnya.
+func SetLinkCode(db *sql.DB, userID, code string, expiresAt time.Time) error {
+	defer observeDBQuery("set_link_code")()
+	query := `INSERT INTO link_codes (code, user_id, expires_at) VALUES (?, ?, ?)
+              ON CONFLICT(user_id) DO UPDATE SET code = excluded.code, expires_at = excluded.expires_at`
+	_, err := db.Exec(query, code, userID, expiresAt.Unix())
+	return err
+}
+
+// RedeemLinkCode memakai kode sekali pakai dan menautkan akun reader ke user Discord pemilik kode.
+// Mengembalikan sql.ErrNoRows jika kode tidak ada atau sudah kedaluwarsa.
+func RedeemLinkCode(db *sql.DB, code, readerUserID string, now time.Time) (string, error) {
+	defer observeDBQuery("redeem_link_code")()
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRow(`SELECT user_id FROM link_codes WHERE code = ? AND expires_at > ?`, code, now.Unix()).Scan(&userID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM link_codes WHERE code = ?`, code); err != nil {
+		return "", err
+	}
+	// Satu akun Discord hanya tertaut ke satu akun reader, dan sebaliknya
+	if _, err := tx.Exec(`DELETE FROM reader_accounts WHERE user_id = ? OR reader_user_id = ?`, userID, readerUserID); err != nil {
+		return "", err
+	}
+	query := `INSERT INTO reader_accounts (reader_user_id, user_id, linked_at) VALUES (?, ?, ?)`
+	if _, err := tx.Exec(query, readerUserID, userID, now.Unix()); err != nil {
+		return "", err
+	}
+	return userID, tx.Commit()
+}
+
+func GetUserByReaderAccount(db *sql.DB, readerUserID string) (string, error) {
+	defer observeDBQuery("get_user_by_reader_account")()
+	var userID string
+	err := db.QueryRow(`SELECT user_id FROM reader_accounts WHERE reader_user_id = ?`, readerUserID).Scan(&userID)
+	return userID, err
+}
+
+func UnlinkReaderAccount(db *sql.DB, userID string) (bool, error) {
+	defer observeDBQuery("unlink_reader_account")()
+	res, err := db.Exec(`DELETE FROM reader_accounts WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func PurgeExpiredLinkCodes(db *sql.DB, now time.Time) error {
+	defer observeDBQuery("purge_expired_link_codes")()
+	_, err := db.Exec(`DELETE FROM link_codes WHERE expires_at <= ?`, now.Unix())
+	return err
+}
+
+// NotificationPrefs adalah saluran notifikasi yang dipilih user.
+type NotificationPrefs struct {
+	UserID        string
+	Discord       bool
+	Email         string // kosong berarti email nonaktif
+	PushURL       string // kosong berarti push nonaktif
+	DigestMode    string // kosong berarti notifikasi langsung
+	DigestHour    int
+	DigestWeekday int // 0 = Minggu, sesuai time.Weekday
+	LastDigestAt  time.Time
+	Timezone      string // nama IANA; kosong berarti defaultTimezone
+	QuietStart    int    // jam mulai jam tenang, -1 berarti nonaktif
+	QuietEnd      int
+}
+
+func defaultNotificationPrefs(userID string) NotificationPrefs {
+	return NotificationPrefs{UserID: userID, Discord: true, DigestHour: 8, DigestWeekday: int(time.Monday), QuietStart: -1, QuietEnd: -1}
+}
+
+const notificationPrefsColumns = `discord, email, push_url, digest_mode, digest_hour, digest_weekday, COALESCE(last_digest_at, 0),
+              timezone, quiet_start, quiet_end`
+
+func scanNotificationPrefs(row interface{ Scan(...any) error }, p *NotificationPrefs, extra ...any) error {
+	var lastDigestAt int64
+	dest := append(extra, &p.Discord, &p.Email, &p.PushURL, &p.DigestMode, &p.DigestHour, &p.DigestWeekday, &lastDigestAt,
+		&p.Timezone, &p.QuietStart, &p.QuietEnd)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if lastDigestAt > 0 {
+		p.LastDigestAt = time.Unix(lastDigestAt, 0)
+	}
+	return nil
+}
+
+func GetNotificationPrefs(db *sql.DB, userID string) (NotificationPrefs, error) {
+	defer observeDBQuery("get_notification_prefs")()
+	prefs := defaultNotificationPrefs(userID)
+	query := `SELECT ` + notificationPrefsColumns + ` FROM notification_prefs WHERE user_id = ?`
+	err := scanNotificationPrefs(db.QueryRow(query, userID), &prefs)
+	if err == sql.ErrNoRows {
+		return defaultNotificationPrefs(userID), nil
+	}
+	return prefs, err
+}
+
+// GetNotificationPrefsForManga mengembalikan pilihan notifikasi semua watcher manga.
+func GetNotificationPrefsForManga(db *sql.DB, mangaID string) ([]NotificationPrefs, error) {
+	defer observeDBQuery("get_notification_prefs_for_manga")()
+	query := `SELECT w.user_id, COALESCE(p.discord, 1), COALESCE(p.email, ''), COALESCE(p.push_url, ''),
+              COALESCE(p.digest_mode, ''), COALESCE(p.digest_hour, 8), COALESCE(p.digest_weekday, 1), COALESCE(p.last_digest_at, 0),
+              COALESCE(p.timezone, ''), COALESCE(p.quiet_start, -1), COALESCE(p.quiet_end, -1)
+              FROM watchlist w LEFT JOIN notification_prefs p ON p.user_id = w.user_id
+              WHERE w.manga_id = ? AND w.deleted_at IS NULL`
+	rows, err := db.Query(query, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var prefs []NotificationPrefs
+	for rows.Next() {
+		var p NotificationPrefs
+		if err := scanNotificationPrefs(rows, &p, &p.UserID); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+// GetDigestSubscribers mengembalikan user yang memakai mode digest atau punya notifikasi
+// yang tertahan selama jam tenang.
+func GetDigestSubscribers(db *sql.DB) ([]NotificationPrefs, error) {
+	defer observeDBQuery("get_digest_subscribers")()
+	query := `SELECT user_id, ` + notificationPrefsColumns + ` FROM notification_prefs
+              WHERE digest_mode != '' OR user_id IN (SELECT user_id FROM digest_queue)`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var prefs []NotificationPrefs
+	for rows.Next() {
+		var p NotificationPrefs
+		if err := scanNotificationPrefs(rows, &p, &p.UserID); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+func SetNotificationPrefs(db *sql.DB, prefs NotificationPrefs) error {
+	defer observeDBQuery("set_notification_prefs")()
+	var lastDigestAt sql.NullInt64
+	if !prefs.LastDigestAt.IsZero() {
+		lastDigestAt = sql.NullInt64{Int64: prefs.LastDigestAt.Unix(), Valid: true}
+	}
+	query := `INSERT INTO notification_prefs (user_id, discord, email, push_url, digest_mode, digest_hour, digest_weekday, last_digest_at,
+              timezone, quiet_start, quiet_end)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(user_id) DO UPDATE SET discord = excluded.discord, email = excluded.email, push_url = excluded.push_url,
+              digest_mode = excluded.digest_mode, digest_hour = excluded.digest_hour, digest_weekday = excluded.digest_weekday,
+              last_digest_at = excluded.last_digest_at, timezone = excluded.timezone,
+              quiet_start = excluded.quiet_start, quiet_end = excluded.quiet_end`
+	_, err := db.Exec(query, prefs.UserID, prefs.Discord, prefs.Email, prefs.PushURL,
+		prefs.DigestMode, prefs.DigestHour, prefs.DigestWeekday, lastDigestAt,
+		prefs.Timezone, prefs.QuietStart, prefs.QuietEnd)
+	return err
+}
+
+func SetLastDigestAt(db *sql.DB, userID string, at time.Time) error {
+	defer observeDBQuery("set_last_digest_at")()
+	_, err := db.Exec(`UPDATE notification_prefs SET last_digest_at = ? WHERE user_id = ?`, at.Unix(), userID)
+	return err
+}
+
+func QueueDigestChapter(db *sql.DB, userID, mangaID string, chapter *Chapter) error {
+	defer observeDBQuery("queue_digest_chapter")()
+	query := `INSERT OR IGNORE INTO digest_queue (user_id, manga_id, chapter_id, chapter_number, queued_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := db.Exec(query, userID, mangaID, chapter.ID, chapter.Number, time.Now().Unix())
+	return err
+}
+
+// DigestEntry adalah satu chapter di digest user.
+type DigestEntry struct {
+	MangaID       string
+	MangaTitle    string
+	ChapterID     string
+	ChapterNumber float64
+}
+
+// GetPendingDigest mengembalikan antrean digest user yang masih relevan: series masih di
+// watchlist dan chapter belum ditandai terbaca. Diurutkan per series lalu nomor chapter.
+func GetPendingDigest(db *sql.DB, userID string, queuedBefore time.Time) ([]DigestEntry, error) {
+	defer observeDBQuery("get_pending_digest")()
+	query := `SELECT q.manga_id, w.manga_title, q.chapter_id, q.chapter_number
+              FROM digest_queue q JOIN watchlist w ON w.user_id = q.user_id AND w.manga_id = q.manga_id
+              WHERE q.user_id = ? AND q.queued_at <= ? AND w.deleted_at IS NULL
+                AND q.chapter_number > w.user_progress_chapter_number
+              ORDER BY w.manga_title ASC, q.manga_id, q.chapter_number ASC`
+	rows, err := db.Query(query, userID, queuedBefore.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []DigestEntry
+	for rows.Next() {
+		var e DigestEntry
+		if err := rows.Scan(&e.MangaID, &e.MangaTitle, &e.ChapterID, &e.ChapterNumber); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ClearDigestQueue menghapus antrean yang sudah diproses, termasuk chapter yang sudah terbaca.
+func ClearDigestQueue(db *sql.DB, userID string, queuedBefore time.Time) error {
+	defer observeDBQuery("clear_digest_queue")()
+	_, err := db.Exec(`DELETE FROM digest_queue WHERE user_id = ? AND queued_at <= ?`, userID, queuedBefore.Unix())
+	return err
+}
+
+// GetSeriesRole mengembalikan role series; sql.ErrNoRows jika belum dibuat.
+func GetSeriesRole(db *sql.DB, mangaID string) (guildID, roleID string, err error) {
+	defer observeDBQuery("get_series_role")()
+	err = db.QueryRow(`SELECT guild_id, role_id FROM series_roles WHERE manga_id = ?`, mangaID).Scan(&guildID, &roleID)
+	return guildID, roleID, err
+}
+
+func SetSeriesRole(db *sql.DB, mangaID, guildID, roleID string) error {
+	defer observeDBQuery("set_series_role")()
+	query := `INSERT INTO series_roles (manga_id, guild_id, role_id) VALUES (?, ?, ?)
+              ON CONFLICT(manga_id) DO UPDATE SET guild_id = excluded.guild_id, role_id = excluded.role_id`
+	_, err := db.Exec(query, mangaID, guildID, roleID)
+	return err
+}
+
+// DeleteSeriesRole dipakai saat role dihapus manual dari server, beserta catatan anggotanya.
+func DeleteSeriesRole(db *sql.DB, mangaID string) error {
+	defer observeDBQuery("delete_series_role")()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM series_role_members WHERE manga_id = ?`, mangaID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM series_roles WHERE manga_id = ?`, mangaID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func GetSeriesRoleMembers(db *sql.DB, mangaID string) (map[string]bool, error) {
+	defer observeDBQuery("get_series_role_members")()
+	rows, err := db.Query(`SELECT user_id FROM series_role_members WHERE manga_id = ?`, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := make(map[string]bool)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		members[userID] = true
+	}
+	return members, rows.Err()
+}
+
+func AddSeriesRoleMember(db *sql.DB, mangaID, userID string) error {
+	defer observeDBQuery("add_series_role_member")()
+	_, err := db.Exec(`INSERT OR IGNORE INTO series_role_members (manga_id, user_id) VALUES (?, ?)`, mangaID, userID)
+	return err
+}
+
+func RemoveSeriesRoleMember(db *sql.DB, mangaID, userID string) error {
+	defer observeDBQuery("remove_series_role_member")()
+	_, err := db.Exec(`DELETE FROM series_role_members WHERE manga_id = ? AND user_id = ?`, mangaID, userID)
+	return err
+}
+
+// GetSeenChapter mengembalikan chapter dari ledger seen_chapters.
+func GetSeenChapter(db *sql.DB, mangaID, chapterID string) (*Chapter, error) {
+	defer observeDBQuery("get_seen_chapter")()
+	chapter := &Chapter{ID: chapterID, MangaID: mangaID}
+	query := `SELECT chapter_number, release_date FROM seen_chapters WHERE manga_id = ? AND chapter_id = ?`
+	err := db.QueryRow(query, mangaID, chapterID).Scan(&chapter.Number, &chapter.ReleaseDate)
+	if err != nil {
+		return nil, err
+	}
+	return chapter, nil
+}
+
+func SnoozeChapter(db *sql.DB, userID, mangaID, chapterID string, remindAt time.Time) error {
+	defer observeDBQuery("snooze_chapter")()
+	query := `INSERT INTO snoozed_chapters (user_id, manga_id, chapter_id, remind_at) VALUES (?, ?, ?, ?)
+              ON CONFLICT(user_id, chapter_id) DO UPDATE SET remind_at = excluded.remind_at`
+	_, err := db.Exec(query, userID, mangaID, chapterID, remindAt.Unix())
+	return err
+}
+
+// SnoozedChapter adalah pengingat yang sudah jatuh tempo. Watching false jika series sudah
+// dihapus dari watchlist user.
+type SnoozedChapter struct {
+	UserID                    string
+	Manga                     Manga
+	Chapter                   Chapter
+	Watching                  bool
+	UserProgressChapterNumber float64
+}
+
+func GetDueSnoozes(db *sql.DB, now time.Time) ([]SnoozedChapter, error) {
+	defer observeDBQuery("get_due_snoozes")()
+	query := `SELECT z.user_id, z.manga_id, COALESCE(w.manga_title, ''), COALESCE(m.cover_url, ''),
+              z.chapter_id, s.chapter_number, s.release_date,
+              w.manga_id IS NOT NULL, COALESCE(w.user_progress_chapter_number, 0)
+              FROM snoozed_chapters z
+              JOIN seen_chapters s ON s.manga_id = z.manga_id AND s.chapter_id = z.chapter_id
+              LEFT JOIN watchlist w ON w.user_id = z.user_id AND w.manga_id = z.manga_id AND w.deleted_at IS NULL
+              LEFT JOIN manga_updates m ON m.manga_id = z.manga_id
+              WHERE z.remind_at <= ?`
+	rows, err := db.Query(query, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var snoozes []SnoozedChapter
+	for rows.Next() {
+		var z SnoozedChapter
+		if err := rows.Scan(&z.UserID, &z.Manga.ID, &z.Manga.Title, &z.Manga.CoverURL,
+			&z.Chapter.ID, &z.Chapter.Number, &z.Chapter.ReleaseDate, &z.Watching, &z.UserProgressChapterNumber); err != nil {
+			return nil, err
+		}
+		z.Chapter.MangaID = z.Manga.ID
+		snoozes = append(snoozes, z)
+	}
+	return snoozes, rows.Err()
+}
+
+func DeleteSnooze(db *sql.DB, userID, chapterID string) error {
+	defer observeDBQuery("delete_snooze")()
+	_, err := db.Exec(`DELETE FROM snoozed_chapters WHERE user_id = ? AND chapter_id = ?`, userID, chapterID)
+	return err
+}
+
+// EmailVerification adalah kode konfirmasi yang dikirim ke alamat email baru.
+type EmailVerification struct {
+	Email     string
+	Code      string
+	SentAt    time.Time
+	ExpiresAt time.Time
+	Attempts  int
+}
+
+func GetEmailVerification(db *sql.DB, userID string) (*EmailVerification, error) {
+	defer observeDBQuery("get_email_verification")()
+	var v EmailVerification
+	var sentAt, expiresAt int64
+	query := `SELECT email, code, sent_at, expires_at, attempts FROM email_verifications WHERE user_id = ?`
+	if err := db.QueryRow(query, userID).Scan(&v.Email, &v.Code, &sentAt, &expiresAt, &v.Attempts); err != nil {
+		return nil, err
+	}
+	v.SentAt, v.ExpiresAt = time.Unix(sentAt, 0), time.Unix(expiresAt, 0)
+	return &v, nil
+}
+
+func SetEmailVerification(db *sql.DB, userID string, v EmailVerification) error {
+	defer observeDBQuery("set_email_verification")()
+	query := `INSERT INTO email_verifications (user_id, email, code, sent_at, expires_at, attempts) VALUES (?, ?, ?, ?, ?, 0)
+              ON CONFLICT(user_id) DO UPDATE SET email = excluded.email, code = excluded.code,
+              sent_at = excluded.sent_at, expires_at = excluded.expires_at, attempts = 0`
+	_, err := db.Exec(query, userID, v.Email, v.Code, v.SentAt.Unix(), v.ExpiresAt.Unix())
+	return err
+}
+
+func RecordEmailVerificationAttempt(db *sql.DB, userID string) error {
+	defer observeDBQuery("record_email_verification_attempt")()
+	_, err := db.Exec(`UPDATE email_verifications SET attempts = attempts + 1 WHERE user_id = ?`, userID)
+	return err
+}
+
+func DeleteEmailVerification(db *sql.DB, userID string) error {
+	defer observeDBQuery("delete_email_verification")()
+	_, err := db.Exec(`DELETE FROM email_verifications WHERE user_id = ?`, userID)
+	return err
+}
+
+func PurgeExpiredEmailVerifications(db *sql.DB, now time.Time) error {
+	defer observeDBQuery("purge_expired_email_verifications")()
+	_, err := db.Exec(`DELETE FROM email_verifications WHERE expires_at <= ?`, now.Unix())
+	return err
+}
@@ -0,0 +1,164 @@
+// digest.go (Mode ringkasan: chapter baru dikumpulkan lalu dikirim harian/mingguan)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	digestDaily  = "daily"
+	digestWeekly = "weekly"
+	// Nilai pilihan Discord tidak boleh kosong; disimpan sebagai mode ''
+	digestInstant = "instant"
+
+	// Batas agar embed tetap di bawah limit 6000 karakter Discord
+	digestMaxSeries         = 15
+	digestChaptersPerSeries = 3
+)
+
+var weekdayNames = []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+
+// lastDigestSlot mengembalikan jadwal digest terakhir yang tidak lebih dari now, dihitung
+// di zona waktu user.
+func lastDigestSlot(prefs NotificationPrefs, now time.Time) time.Time {
+	loc := prefs.Location()
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), prefs.DigestHour, 0, 0, 0, loc)
+	days := 1
+	if prefs.DigestMode == digestWeekly {
+		days = 7
+		slot = slot.AddDate(0, 0, -((int(local.Weekday()) - prefs.DigestWeekday + 7) % 7))
+	}
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -days)
+	}
+	return slot
+}
+
+// sendDueDigests mengirim digest milik user yang jadwalnya sudah lewat sejak digest terakhir,
+// serta notifikasi yang tertahan setelah jam tenang berakhir. Digest yang jatuh tempo di
+// jam tenang ikut ditunda.
+func sendDueDigests(ctx context.Context) {
+	subscribers, err := GetDigestSubscribers(db)
+	if err != nil {
+		slog.Error("Failed to get digest subscribers", "error", err)
+		return
+	}
+	now := time.Now()
+	for _, prefs := range subscribers {
+		if ctx.Err() != nil {
+			return
+		}
+		if prefs.InQuietHours(now) {
+			continue
+		}
+		if prefs.DigestMode == "" || prefs.LastDigestAt.Before(lastDigestSlot(prefs, now)) {
+			sendDigest(ctx, prefs, now)
+		}
+	}
+}
+
+// sendDigest mengirim antrean digest user. Jika semua saluran gagal, antrean digest disimpan
+// untuk jadwal berikutnya alih-alih dicoba ulang tiap menit; notifikasi yang hanya tertahan
+// jam tenang dibuang seperti notifikasi langsung yang gagal.
+func sendDigest(ctx context.Context, prefs NotificationPrefs, now time.Time) {
+	// Chapter yang masuk antrean di detik yang sama ikut digest berikutnya
+	cutoff := now.Add(-time.Second)
+	entries, err := GetPendingDigest(db, prefs.UserID, cutoff)
+	if err != nil {
+		slog.Error("Failed to get pending digest", "user_id", prefs.UserID, "error", err)
+		return
+	}
+
+	clearQueue := true
+	if len(entries) > 0 {
+		n := Notification{Kind: NotificationDigest, Digest: entries}
+		if _, err := deliverNotification(ctx, n, []NotificationPrefs{prefs}); err != nil {
+			slog.Warn("Failed to send digest", "user_id", prefs.UserID, "mode", prefs.DigestMode, "error", err)
+			clearQueue = prefs.DigestMode == ""
+		} else {
+			slog.Info("Digest sent", "user_id", prefs.UserID, "chapters", len(entries))
+		}
+	}
+	if clearQueue {
+		if err := ClearDigestQueue(db, prefs.UserID, cutoff); err != nil {
+			slog.Error("Failed to clear digest queue", "user_id", prefs.UserID, "error", err)
+			return
+		}
+	}
+	if err := SetLastDigestAt(db, prefs.UserID, now); err != nil {
+		slog.Error("Failed to save last digest time", "user_id", prefs.UserID, "error", err)
+	}
+}
+
+// createDigestEmbed mengelompokkan chapter per series; entries sudah terurut per series.
+func createDigestEmbed(entries []DigestEntry) *discordgo.MessageEmbed {
+	type seriesGroup struct {
+		title    string
+		chapters []DigestEntry
+	}
+	var groups []*seriesGroup
+	for idx, e := range entries {
+		if idx == 0 || entries[idx-1].MangaID != e.MangaID {
+			groups = append(groups, &seriesGroup{title: e.MangaTitle})
+		}
+		group := groups[len(groups)-1]
+		group.chapters = append(group.chapters, e)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{Name: "📬 Ringkasan Chapter Baru"},
+		Description: fmt.Sprintf("**%d** chapter baru dari **%d** series di watchlist Anda.", len(entries), len(groups)),
+		Color:       0xffa500,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Eveeze Comic Bot", IconURL: "https://i.imgur.com/R4Ifj2p.png"},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	for idx, group := range groups {
+		if idx == digestMaxSeries {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  "…",
+				Value: fmt.Sprintf("dan %d series lainnya. Buka `/watchlist` untuk melihat semuanya.", len(groups)-digestMaxSeries),
+			})
+			break
+		}
+		var links []string
+		for _, chapter := range group.chapters {
+			if len(links) == digestChaptersPerSeries {
+				links = append(links, fmt.Sprintf("+%d lainnya", len(group.chapters)-digestChaptersPerSeries))
+				break
+			}
+			links = append(links, fmt.Sprintf("[Ch. %.1f](%s/chapter/%s)", chapter.ChapterNumber, cfg.ReaderBaseURL, chapter.ChapterID))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  truncateTitle(group.title, 100),
+			Value: strings.Join(links, " · "),
+		})
+	}
+	return embed
+}
+
+func describeDigestMode(prefs NotificationPrefs) string {
+	switch prefs.DigestMode {
+	case digestDaily:
+		return fmt.Sprintf("ringkasan harian pukul %02d:00 (%s)", prefs.DigestHour, prefs.TimezoneName())
+	case digestWeekly:
+		return fmt.Sprintf("ringkasan mingguan setiap %s pukul %02d:00 (%s)", weekdayNames[prefs.DigestWeekday], prefs.DigestHour, prefs.TimezoneName())
+	}
+	return "langsung saat chapter rilis"
+}
+
+func weekdayChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(weekdayNames))
+	// Mulai dari Senin agar urutan pilihan mengikuti kalender lokal
+	for offset := 1; offset <= len(weekdayNames); offset++ {
+		day := offset % len(weekdayNames)
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: weekdayNames[day], Value: day})
+	}
+	return choices
+}
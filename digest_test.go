@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastDigestSlot(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, wib)
+	}
+	// 1 Juni 2024 adalah hari Sabtu
+	tests := []struct {
+		name  string
+		prefs NotificationPrefs
+		now   time.Time
+		want  time.Time
+	}{
+		{"daily, after today's slot", NotificationPrefs{DigestMode: digestDaily, DigestHour: 8}, at(6, 1, 9, 0), at(6, 1, 8, 0)},
+		{"daily, before today's slot", NotificationPrefs{DigestMode: digestDaily, DigestHour: 8}, at(6, 1, 7, 30), at(5, 31, 8, 0)},
+		{"daily, exactly at slot", NotificationPrefs{DigestMode: digestDaily, DigestHour: 8}, at(6, 1, 8, 0), at(6, 1, 8, 0)},
+		{"daily, local day differs from UTC", NotificationPrefs{DigestMode: digestDaily, DigestHour: 2}, at(6, 1, 3, 0), at(6, 1, 2, 0)},
+		{"weekly, later in the week", NotificationPrefs{DigestMode: digestWeekly, DigestHour: 8, DigestWeekday: int(time.Monday)}, at(6, 1, 9, 0), at(5, 27, 8, 0)},
+		{"weekly, on the day before the slot", NotificationPrefs{DigestMode: digestWeekly, DigestHour: 8, DigestWeekday: int(time.Monday)}, at(6, 3, 7, 0), at(5, 27, 8, 0)},
+		{"weekly, on the day after the slot", NotificationPrefs{DigestMode: digestWeekly, DigestHour: 8, DigestWeekday: int(time.Monday)}, at(6, 3, 8, 0), at(6, 3, 8, 0)},
+		{"weekly on Sunday, checked on Saturday", NotificationPrefs{DigestMode: digestWeekly, DigestHour: 8, DigestWeekday: int(time.Sunday)}, at(6, 1, 23, 0), at(5, 26, 8, 0)},
+		// Jam digest mengikuti zona waktu user, termasuk DST: 08:00 BST adalah 07:00 UTC, 08:00 GMT adalah 08:00 UTC
+		{"daily in user timezone, summer", NotificationPrefs{DigestMode: digestDaily, DigestHour: 8, Timezone: "Europe/London"},
+			time.Date(2024, 6, 1, 7, 30, 0, 0, time.UTC), time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)},
+		{"daily in user timezone, winter", NotificationPrefs{DigestMode: digestDaily, DigestHour: 8, Timezone: "Europe/London"},
+			time.Date(2024, 1, 6, 7, 30, 0, 0, time.UTC), time.Date(2024, 1, 5, 8, 0, 0, 0, time.UTC)},
+		{"weekly in user timezone", NotificationPrefs{DigestMode: digestWeekly, DigestHour: 20, DigestWeekday: int(time.Friday), Timezone: "America/New_York"},
+			time.Date(2024, 6, 1, 1, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lastDigestSlot(tt.prefs, tt.now.UTC())
+			if !got.Equal(tt.want) {
+				t.Errorf("lastDigestSlot() = %s, want %s", got, tt.want)
+			}
+
+			// Digest jatuh tempo sekali per jadwal: setelah dikirim, baru jatuh tempo lagi di jadwal berikutnya
+			prefs := tt.prefs
+			prefs.LastDigestAt = got.Add(-time.Minute)
+			if !prefs.LastDigestAt.Before(lastDigestSlot(prefs, tt.now)) {
+				t.Error("digest sent before the slot should be due")
+			}
+			prefs.LastDigestAt = tt.now
+			if prefs.LastDigestAt.Before(lastDigestSlot(prefs, tt.now)) {
+				t.Error("digest sent at now should not be due again")
+			}
+		})
+	}
+}
@@ -2,13 +2,14 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -18,16 +19,39 @@ var (
 	cacheMutex        = &sync.Mutex{}
 )
 
+// interactionUserID mengembalikan user yang memicu interaksi. i.Member hanya terisi di server;
+// di DM (perintah tanpa DMPermission: false, tombol di pesan DM) yang terisi i.User.
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return interactionUserID(i)
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
 func interactionHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	start := time.Now()
+	defer func() { logFor(i).Debug("Interaction handled", "type", i.Type.String(), "duration", time.Since(start)) }()
+	rememberGuildUser(i)
+
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
 		// Rute untuk perintah slash seperti /search dan /watchlist
 		if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
+			interactionsTotal.WithLabelValues("command", i.ApplicationCommandData().Name).Inc()
 			h(s, i)
 		}
 	case discordgo.InteractionMessageComponent:
 		// Handler untuk komponen seperti tombol
+		interactionsTotal.WithLabelValues("component", componentAction(i.MessageComponentData().CustomID)).Inc()
 		componentHandler(s, i)
+	case discordgo.InteractionModalSubmit:
+		interactionsTotal.WithLabelValues("modal", i.ModalSubmitData().CustomID).Inc()
+		if i.ModalSubmitData().CustomID == "bulk_shelf_modal" {
+			bulkModalHandler(s, i)
+		}
 	}
 }
 
@@ -36,14 +60,14 @@ func searchCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate)
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 	if err != nil {
-		log.Printf("Could not defer interaction for /search: %v", err)
+		logFor(i).Error("Could not defer interaction for /search", "error", err)
 		return
 	}
 
 	query := i.ApplicationCommandData().Options[0].StringValue()
 	response, err := createSearchResponseMessage(query, 1)
 	if err != nil {
-		log.Printf("Error creating search response: %v", err)
+		logFor(i).Warn("Error creating search response", "query", query, "error", err)
 		content := "❌ Gagal mencari manga atau tidak ada hasil untuk: **" + query + "**"
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
 		return
@@ -51,19 +75,100 @@ func searchCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate)
 	s.InteractionResponseEdit(i.Interaction, response)
 }
 
+func watchCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		logFor(i).Error("Could not defer /watch", "error", err)
+		return
+	}
+
+	input := i.ApplicationCommandData().Options[0].StringValue()
+	link, err := parseReaderLink(input)
+	if err != nil {
+		msg := fmt.Sprintf("❌ Link tidak dikenali. Gunakan link manga/chapter dari %s atau ID manga.", cfg.ReaderBaseURL)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+		return
+	}
+	manga, chapter, err := resolveReaderLink(link)
+	if err != nil {
+		logFor(i).Warn("Failed to resolve reader link", "link", input, "error", err)
+		msg := "❌ Manga atau chapter tidak ditemukan."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+		return
+	}
+
+	userID := interactionUserID(i)
+	_, err = GetWatchlistItem(db, userID, manga.ID)
+	alreadyWatched := err == nil
+	if !alreadyWatched {
+		if err := watchManga(userID, manga); err != nil {
+			logFor(i).Error("Failed to add to watchlist via /watch", "manga_id", manga.ID, "error", err)
+			msg := "Gagal menambahkan ke watchlist."
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+			return
+		}
+	}
+
+	var msg string
+	if chapter != nil {
+		if err := UpdateUserProgress(db, userID, manga.ID, chapter.ID, chapter.Number); err != nil {
+			logFor(i).Error("Failed to update progress via /watch", "manga_id", manga.ID, "error", err)
+		}
+		if alreadyWatched {
+			msg = fmt.Sprintf("✅ Progres **%s** diperbarui ke chapter **%.1f**.", manga.Title, chapter.Number)
+		} else {
+			msg = fmt.Sprintf("✅ **%s** berhasil ditambahkan ke watchlist! Progres diatur ke chapter **%.1f**.", manga.Title, chapter.Number)
+		}
+	} else if alreadyWatched {
+		msg = fmt.Sprintf("ℹ️ **%s** sudah ada di watchlist Anda.", manga.Title)
+	} else {
+		msg = fmt.Sprintf("✅ **%s** berhasil ditambahkan ke watchlist!", manga.Title)
+	}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+}
+
+// watchManga menambahkan manga ke watchlist user dengan progres di chapter terbaru.
+func watchManga(userID string, manga *Manga) error {
+	latestChapter, err := GetLatestChapter(manga.ID)
+	if err != nil {
+		latestChapter = &Chapter{ID: "0", Number: 0}
+	}
+	return AddToWatchlist(db, WatchlistItem{
+		MangaID: manga.ID, UserID: userID, MangaTitle: manga.Title,
+		UserProgressChapterID: latestChapter.ID, UserProgressChapterNumber: latestChapter.Number,
+	})
+}
+
 func watchlistCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if options := i.ApplicationCommandData().Options; len(options) > 0 {
+		switch options[0].Name {
+		case "export":
+			watchlistExportHandler(s, i, options[0])
+			return
+		case "import":
+			watchlistImportHandler(s, i, options[0])
+			return
+		case "bulk":
+			watchlistBulkHandler(s, i)
+			return
+		}
+	}
+
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
 	})
 	if err != nil {
-		log.Printf("Could not defer /watchlist: %v", err)
+		logFor(i).Error("Could not defer /watchlist", "error", err)
 		return
 	}
 
-	response, err := createWatchlistResponseMessage(i.Member.User.ID, 1)
+	response, err := createWatchlistResponseMessage(interactionUserID(i), 1)
 	if err != nil {
-		log.Printf("Error creating watchlist response: %v", err)
+		logFor(i).Error("Error creating watchlist response", "error", err)
 		content := "Gagal mengambil watchlist."
 		response = &discordgo.WebhookEdit{Content: &content}
 	}
@@ -93,7 +198,7 @@ func componentHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			latestChapter = &Chapter{ID: "0", Number: 0}
 		}
 		item := WatchlistItem{
-			MangaID: manga.ID, UserID: i.Member.User.ID, MangaTitle: manga.Title,
+			MangaID: manga.ID, UserID: interactionUserID(i), MangaTitle: manga.Title,
 			UserProgressChapterID: latestChapter.ID, UserProgressChapterNumber: latestChapter.Number,
 		}
 		if err := AddToWatchlist(db, item); err != nil {
@@ -112,7 +217,7 @@ func componentHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
 		})
 		mangaID := strings.TrimPrefix(customID, "show_unread_")
-		userID := i.Member.User.ID
+		userID := interactionUserID(i)
 		watchlistItem, err := GetWatchlistItem(db, userID, mangaID)
 		if err != nil {
 			msg := "Gagal mendapatkan data watchlist."
@@ -168,10 +273,10 @@ func componentHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		mangaID := parts[2]
 		newChapterID := parts[3]
 		newChapterNumber, _ := strconv.ParseFloat(strings.Join(parts[4:], "."), 64)
-		userID := i.Member.User.ID
+		userID := interactionUserID(i)
 		err := UpdateUserProgress(db, userID, mangaID, newChapterID, newChapterNumber)
 		if err != nil {
-			log.Printf("Failed to update user progress: %v", err)
+			logFor(i).Error("Failed to update user progress", "manga_id", mangaID, "error", err)
 			return
 		}
 		msg := "✅ Progres Anda telah diperbarui! Jalankan `/watchlist` lagi untuk melihat."
@@ -184,19 +289,19 @@ func componentHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	if strings.HasPrefix(customID, "mark_latest_") {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
 		mangaID := strings.TrimPrefix(customID, "mark_latest_")
-		userID := i.Member.User.ID
+		userID := interactionUserID(i)
 
 		// Dapatkan chapter terbaru langsung dari API
 		latestChapter, err := GetLatestChapter(mangaID)
 		if err != nil {
-			log.Printf("Failed to get latest chapter for mark_latest: %v", err)
+			logFor(i).Error("Failed to get latest chapter for mark_latest", "manga_id", mangaID, "error", err)
 			return
 		}
 
 		// Update progres di database ke chapter terbaru
 		err = UpdateUserProgress(db, userID, mangaID, latestChapter.ID, latestChapter.Number)
 		if err != nil {
-			log.Printf("Failed to update user progress for mark_latest: %v", err)
+			logFor(i).Error("Failed to update user progress for mark_latest", "manga_id", mangaID, "error", err)
 			return
 		}
 		
@@ -212,16 +317,41 @@ func componentHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	if strings.HasPrefix(customID, "delete_watchlist_") {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
 		mangaID := strings.TrimPrefix(customID, "delete_watchlist_")
-		userID := i.Member.User.ID
-		if err := DeleteFromWatchlist(db, mangaID, userID); err != nil {
-			log.Printf("Failed to delete from watchlist: %v", err)
-			return
-		}
-		response, err := createWatchlistResponseMessage(userID, 1)
+		userID := interactionUserID(i)
+		item, err := GetWatchlistItem(db, userID, mangaID)
 		if err != nil {
+			logFor(i).Error("Failed to get watchlist item for delete", "manga_id", mangaID, "error", err)
 			return
 		}
-		s.InteractionResponseEdit(i.Interaction, response)
+		s.InteractionResponseEdit(i.Interaction, createDeleteConfirmMessage(item))
+		return
+	}
+	if strings.HasPrefix(customID, "confirm_delete_") {
+		confirmDeleteHandler(s, i, customID)
+		return
+	}
+	if strings.HasPrefix(customID, "undo_delete_") {
+		undoDeleteHandler(s, i, customID)
+		return
+	}
+	if strings.HasPrefix(customID, "search_replacement_") || strings.HasPrefix(customID, "unavailable_remove_") {
+		unavailableComponentHandler(s, i, customID)
+		return
+	}
+	if strings.HasPrefix(customID, "notify_") {
+		notificationComponentHandler(s, i, customID)
+		return
+	}
+	if strings.HasPrefix(customID, "unfurl_") {
+		unfurlComponentHandler(s, i, customID)
+		return
+	}
+	if strings.HasPrefix(customID, "bulk_") {
+		bulkComponentHandler(s, i, customID)
+		return
+	}
+	if strings.HasPrefix(customID, "import_") {
+		importComponentHandler(s, i, customID)
 		return
 	}
 	if strings.HasPrefix(customID, "watchlist_page_") {
@@ -314,13 +444,29 @@ func createWatchlistResponseMessage(userID string, page int) (*discordgo.Webhook
 	var components []discordgo.MessageComponent
 
 	for _, item := range items {
+		// Series yang sudah tidak tersedia tidak perlu memanggil API lagi
+		if unavailable, err := IsMangaUnavailable(db, item.MangaID); err == nil && unavailable {
+			embeds = append(embeds, &discordgo.MessageEmbed{
+				Title:       item.MangaTitle,
+				Description: fmt.Sprintf("⚠️ **Tidak Tersedia** — series ini sudah tidak ditemukan di sumber.\nProgres terakhir Anda: chapter **%.1f**.", item.UserProgressChapterNumber),
+				Color:       0x808080,
+			})
+			components = append(components, discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{Label: "🔍 Cari Pengganti", Style: discordgo.PrimaryButton, CustomID: fmt.Sprintf("search_replacement_%s", item.MangaID)},
+					discordgo.Button{Label: "🗑️ Hapus dari Watchlist", Style: discordgo.DangerButton, CustomID: fmt.Sprintf("delete_watchlist_%s", item.MangaID)},
+				},
+			})
+			continue
+		}
+
 		latestChapter, err := GetLatestChapter(item.MangaID)
 		if err != nil {
 			latestChapter = &Chapter{Number: item.UserProgressChapterNumber}
 		}
 		mangaDetails, err := GetMangaDetails(item.MangaID)
 		if err != nil {
-			log.Printf("Could not get manga details for %s: %v", item.MangaID, err)
+			slog.Warn("Could not get manga details", "manga_id", item.MangaID, "error", err)
 		}
 
 		chaptersBehind := math.Max(0, latestChapter.Number-item.UserProgressChapterNumber)
@@ -340,6 +486,9 @@ func createWatchlistResponseMessage(userID string, page int) (*discordgo.Webhook
 		if mangaDetails != nil {
 			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: mangaDetails.CoverURL}
 		}
+		if item.Shelf != "" {
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("📁 Rak: %s", item.Shelf)}
+		}
 		embeds = append(embeds, embed)
 
 		actionRow1 := discordgo.ActionsRow{
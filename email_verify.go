@@ -0,0 +1,122 @@
+// email_verify.go (Konfirmasi alamat email sebelum dipakai sebagai saluran notifikasi)
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	emailCodeTTL         = 15 * time.Minute
+	emailCodeCooldown    = time.Minute // jeda minimal antar pengiriman kode agar bot tidak dipakai untuk spam
+	emailCodeMaxAttempts = 5
+)
+
+func findEmailNotifier() *emailNotifier {
+	for _, notifier := range notifiers {
+		if e, ok := notifier.(*emailNotifier); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// startEmailVerification mengirim kode konfirmasi ke alamat baru. Alamat baru dipakai
+// setelah user memasukkan kodenya lewat /notifikasi verifikasi.
+func startEmailVerification(s *discordgo.Session, i *discordgo.InteractionCreate, address string) {
+	respond := func(msg string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+		})
+	}
+	userID := interactionUserID(i)
+	sender := findEmailNotifier()
+	if sender == nil {
+		respond("❌ Notifikasi email belum diaktifkan oleh operator bot.")
+		return
+	}
+	if pending, err := GetEmailVerification(db, userID); err == nil && time.Since(pending.SentAt) < emailCodeCooldown {
+		respond("⏳ Kode baru saja dikirim. Tunggu sebentar sebelum meminta kode lagi.")
+		return
+	}
+
+	// Pengiriman SMTP bisa lebih lama dari batas 3 detik respons interaksi
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		return
+	}
+	code, err := generateLinkCode()
+	if err != nil {
+		logFor(i).Error("Failed to generate email code", "error", err)
+		editInteractionContent(s, i, "Gagal membuat kode verifikasi.")
+		return
+	}
+	now := time.Now()
+	verification := EmailVerification{Email: address, Code: code, SentAt: now, ExpiresAt: now.Add(emailCodeTTL)}
+	if err := SetEmailVerification(db, userID, verification); err != nil {
+		logFor(i).Error("Failed to save email verification", "error", err)
+		editInteractionContent(s, i, "Gagal membuat kode verifikasi.")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(appCtx, smtpTimeout)
+	defer cancel()
+	body := fmt.Sprintf("Kode verifikasi email Eveeze Comic Bot Anda: %s\n\n"+
+		"Masukkan kode ini dengan /notifikasi verifikasi di Discord. Kode berlaku %.0f menit.\n"+
+		"Abaikan email ini jika Anda tidak memintanya; alamat Anda tidak akan dipakai tanpa kode ini.",
+		code, emailCodeTTL.Minutes())
+	if err := sender.send(ctx, address, "Kode verifikasi email", body); err != nil {
+		logFor(i).Warn("Failed to send email verification", "error", err)
+		DeleteEmailVerification(db, userID)
+		editInteractionContent(s, i, "❌ Gagal mengirim email ke alamat tersebut.")
+		return
+	}
+	logFor(i).Info("Email verification sent")
+	editInteractionContent(s, i, fmt.Sprintf("📧 Kode verifikasi dikirim ke **%s**. Jalankan `/notifikasi verifikasi` dengan kode tersebut dalam %.0f menit untuk mengaktifkan notifikasi email.",
+		address, emailCodeTTL.Minutes()))
+}
+
+// confirmEmailVerification mengembalikan alamat yang terverifikasi, atau pesan error untuk user.
+func confirmEmailVerification(userID, code string, now time.Time) (string, string, error) {
+	pending, err := GetEmailVerification(db, userID)
+	if err == sql.ErrNoRows || (err == nil && !now.Before(pending.ExpiresAt)) {
+		return "", "❌ Tidak ada kode yang menunggu atau kode sudah kedaluwarsa. Atur ulang alamat dengan `/notifikasi email`.", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if pending.Attempts >= emailCodeMaxAttempts {
+		if err := DeleteEmailVerification(db, userID); err != nil {
+			return "", "", err
+		}
+		return "", "❌ Terlalu banyak percobaan. Minta kode baru dengan `/notifikasi email`.", nil
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(code), []byte(pending.Code)) != 1 {
+		if err := RecordEmailVerificationAttempt(db, userID); err != nil {
+			return "", "", err
+		}
+		return "", "❌ Kode tidak cocok.", nil
+	}
+	if err := DeleteEmailVerification(db, userID); err != nil {
+		return "", "", err
+	}
+	return pending.Email, "", nil
+}
+
+func purgeExpiredEmailVerifications() {
+	if err := PurgeExpiredEmailVerifications(db, time.Now()); err != nil {
+		slog.Error("Failed to purge expired email verifications", "error", err)
+	}
+}
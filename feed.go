@@ -0,0 +1,150 @@
+// feed.go (Feed Atom per user: /feed dan endpoint HTTP /feed/<token>)
+package main
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const feedEntryLimit = 50
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Link      atomLink    `xml:"link"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+func feedURL(token string) string {
+	return fmt.Sprintf("%s/feed/%s.atom", cfg.PublicBaseURL, token)
+}
+
+func buildAtomFeed(userID, selfURL string, entries []FeedEntry) *atomFeed {
+	feed := &atomFeed{
+		ID:     "urn:eveeze:feed:" + userID,
+		Title:  "Eveeze Comic — Chapter Baru",
+		Author: "Eveeze Comic Bot",
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: cfg.ReaderBaseURL, Rel: "alternate"},
+		},
+		Updated: time.Now().UTC().Format(time.RFC3339),
+	}
+	for idx, entry := range entries {
+		readerURL := fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, entry.ChapterID)
+		updated := entry.SeenAt.UTC().Format(time.RFC3339)
+		var published string
+		if t, err := time.Parse(time.RFC3339, entry.ReleaseDate); err == nil {
+			published = t.UTC().Format(time.RFC3339)
+		}
+		if idx == 0 {
+			feed.Updated = updated
+		}
+
+		var content strings.Builder
+		if entry.CoverURL != "" {
+			fmt.Fprintf(&content, `<p><img src="%s" alt="%s"/></p>`, html.EscapeString(entry.CoverURL), html.EscapeString(entry.MangaTitle))
+		}
+		fmt.Fprintf(&content, `<p><a href="%s">Baca chapter %.1f</a></p>`, html.EscapeString(readerURL), entry.ChapterNumber)
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        "urn:eveeze:chapter:" + entry.ChapterID,
+			Title:     fmt.Sprintf("%s — Chapter %.1f", entry.MangaTitle, entry.ChapterNumber),
+			Updated:   updated,
+			Published: published,
+			Link:      atomLink{Href: readerURL, Rel: "alternate"},
+			Content:   atomContent{Type: "html", Body: content.String()},
+		})
+	}
+	return feed
+}
+
+func feedHTTPHandler(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/feed/"), ".atom")
+	if token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	userID, err := GetUserByFeedToken(db, token)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to get feed token", "error", err)
+		http.Error(w, "failed to read feed", http.StatusInternalServerError)
+		return
+	}
+	entries, err := GetFeedEntriesForUser(db, userID, feedEntryLimit)
+	if err != nil {
+		slog.Error("Failed to get feed entries", "user_id", userID, "error", err)
+		http.Error(w, "failed to read feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(buildAtomFeed(userID, feedURL(token), entries)); err != nil {
+		slog.Error("Failed to write feed", "user_id", userID, "error", err)
+	}
+}
+
+func feedCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	respond := func(msg string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+		})
+	}
+	if cfg.PublicBaseURL == "" {
+		respond("❌ Feed belum tersedia: URL publik bot belum diatur oleh operator.")
+		return
+	}
+
+	rotate := i.ApplicationCommandData().Options[0].Name == "ganti"
+	token, err := GetOrCreateFeedToken(db, interactionUserID(i), rotate)
+	if err != nil {
+		logFor(i).Error("Failed to get feed token", "error", err)
+		respond("Gagal membuat URL feed.")
+		return
+	}
+	msg := "📰 URL feed Atom pribadi Anda (jangan dibagikan):\n" + feedURL(token)
+	if rotate {
+		logFor(i).Info("Feed token rotated")
+		msg = "🔄 URL feed lama sudah tidak berlaku. URL baru Anda:\n" + feedURL(token)
+	}
+	respond(msg + "\n\nTambahkan URL ini ke feed reader favorit Anda untuk mengikuti chapter baru dari watchlist.")
+}
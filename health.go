@@ -0,0 +1,222 @@
+// health.go (Endpoint /healthz dan /readyz)
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	apiErrorWindow        = 15 * time.Minute
+	apiErrorRateThreshold = 0.5
+	apiErrorMinRequests   = 5
+	maxDisconnectDuration = 5 * time.Minute
+	maxUpdateCheckAge     = 2 * time.Hour
+	maxRecentErrors       = 10
+)
+
+type apiResult struct {
+	at     time.Time
+	failed bool
+}
+
+type seriesError struct {
+	At      time.Time
+	MangaID string
+	Error   string
+}
+
+type healthState struct {
+	mu             sync.Mutex
+	startedAt      time.Time
+	connected      bool
+	disconnectedAt time.Time
+	lastCheckAt    time.Time
+	lastSuccessAt  time.Time
+	lastCheckErr   string
+	lastFailures   int
+	apiResults     []apiResult
+	recentErrors   []seriesError
+}
+
+type healthCheck struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type healthReport struct {
+	Status          string                 `json:"status"`
+	Checks          map[string]healthCheck `json:"checks"`
+	LastUpdateCheck *updateCheckReport     `json:"last_update_check"`
+	APIErrorRate    float64                `json:"api_error_rate"`
+	APIRequests     int                    `json:"api_requests"`
+}
+
+type updateCheckReport struct {
+	At             *time.Time `json:"at,omitempty"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	SeriesFailures int        `json:"series_failures"`
+}
+
+var health = &healthState{startedAt: time.Now(), disconnectedAt: time.Now()}
+
+func (h *healthState) setConnected(connected bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.connected && !connected {
+		h.disconnectedAt = time.Now()
+	}
+	h.connected = connected
+}
+
+func (h *healthState) recordUpdateCheck(err error, failures int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastCheckAt = time.Now()
+	h.lastFailures = failures
+	h.lastCheckErr = ""
+	if err != nil {
+		h.lastCheckErr = err.Error()
+		return
+	}
+	h.lastSuccessAt = h.lastCheckAt
+}
+
+func (h *healthState) recordSeriesError(mangaID string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.recentErrors = append(h.recentErrors, seriesError{At: time.Now(), MangaID: mangaID, Error: err.Error()})
+	if len(h.recentErrors) > maxRecentErrors {
+		h.recentErrors = h.recentErrors[len(h.recentErrors)-maxRecentErrors:]
+	}
+}
+
+func (h *healthState) lastErrors() []seriesError {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]seriesError(nil), h.recentErrors...)
+}
+
+func (h *healthState) recordAPIResult(failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.apiResults = append(h.apiResults, apiResult{at: now, failed: failed})
+	// Buang hasil yang sudah di luar window
+	cutoff := now.Add(-apiErrorWindow)
+	idx := 0
+	for idx < len(h.apiResults) && h.apiResults[idx].at.Before(cutoff) {
+		idx++
+	}
+	h.apiResults = h.apiResults[idx:]
+}
+
+func (h *healthState) apiErrorRate() (float64, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cutoff := time.Now().Add(-apiErrorWindow)
+	total, failed := 0, 0
+	for _, r := range h.apiResults {
+		if r.at.Before(cutoff) {
+			continue
+		}
+		total++
+		if r.failed {
+			failed++
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failed) / float64(total), total
+}
+
+// buildHealthReport menjalankan semua pengecekan. ready=true untuk /readyz yang lebih ketat.
+func buildHealthReport(s *discordgo.Session, ready bool) healthReport {
+	report := healthReport{Status: "ok", Checks: make(map[string]healthCheck)}
+	fail := func(name, detail string) {
+		report.Checks[name] = healthCheck{Status: "fail", Detail: detail}
+		report.Status = "fail"
+	}
+
+	health.mu.Lock()
+	connected, disconnectedAt := health.connected, health.disconnectedAt
+	startedAt, lastCheckAt, lastSuccessAt := health.startedAt, health.lastCheckAt, health.lastSuccessAt
+	lastCheckErr, lastFailures := health.lastCheckErr, health.lastFailures
+	health.mu.Unlock()
+
+	// Discord gateway
+	switch {
+	case connected && s.DataReady:
+		report.Checks["discord"] = healthCheck{Status: "ok", Detail: "connected"}
+	case ready:
+		fail("discord", "gateway not ready")
+	case time.Since(disconnectedAt) > maxDisconnectDuration:
+		fail("discord", fmt.Sprintf("disconnected since %s", disconnectedAt.Format(time.RFC3339)))
+	default:
+		report.Checks["discord"] = healthCheck{Status: "ok", Detail: "reconnecting"}
+	}
+
+	// Database
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		fail("database", err.Error())
+	} else {
+		report.Checks["database"] = healthCheck{Status: "ok"}
+	}
+
+	// Update checker
+	checkReport := &updateCheckReport{Error: lastCheckErr, SeriesFailures: lastFailures}
+	if !lastCheckAt.IsZero() {
+		checkReport.At = &lastCheckAt
+	}
+	if !lastSuccessAt.IsZero() {
+		checkReport.LastSuccessAt = &lastSuccessAt
+	}
+	report.LastUpdateCheck = checkReport
+	reference := lastSuccessAt
+	if reference.IsZero() {
+		reference = startedAt
+	}
+	// Seperti source_api, hanya readiness yang gagal: run yang gagal
+	// biasanya disebabkan API sumber dan restart tidak memperbaikinya
+	age := time.Since(reference)
+	switch {
+	case age <= maxUpdateCheckAge:
+		report.Checks["update_check"] = healthCheck{Status: "ok"}
+	case ready:
+		fail("update_check", fmt.Sprintf("no successful update check for %s", age.Round(time.Minute)))
+	default:
+		report.Checks["update_check"] = healthCheck{Status: "ok", Detail: fmt.Sprintf("no successful update check for %s", age.Round(time.Minute))}
+	}
+
+	// Source API: hanya memengaruhi readiness, restart tidak memperbaiki API sumber
+	report.APIErrorRate, report.APIRequests = health.apiErrorRate()
+	if ready && report.APIRequests >= apiErrorMinRequests && report.APIErrorRate >= apiErrorRateThreshold {
+		fail("source_api", fmt.Sprintf("error rate %.0f%% over the last %s", report.APIErrorRate*100, apiErrorWindow))
+	} else {
+		report.Checks["source_api"] = healthCheck{Status: "ok"}
+	}
+
+	return report
+}
+
+func healthHandler(s *discordgo.Session, ready bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := buildHealthReport(s, ready)
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
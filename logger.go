@@ -0,0 +1,69 @@
+// logger.go (Logging terstruktur dengan level)
+package main
+
+import (
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const maxLoggedBodySize = 2048
+
+var secretPatterns = []*regexp.Regexp{
+	// "token": "...", "api_key": "...", dsb. di body JSON
+	regexp.MustCompile(`(?i)("(?:[a-z_]*token|secret|password|api_?key|authorization|signature)"\s*:\s*")[^"]*(")`),
+	// token=... di query string
+	regexp.MustCompile(`(?i)((?:[a-z_]*token|secret|password|api_?key|signature)=)[^&\s"]+()`),
+	regexp.MustCompile(`(?i)(Bearer |Bot )[A-Za-z0-9._\-]+()`),
+}
+
+func setupLogger(cfg *Config) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.LogFormat, "text") {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// logFor mengembalikan logger dengan field dari interaction Discord.
+func logFor(i *discordgo.InteractionCreate) *slog.Logger {
+	logger := slog.With("interaction_id", i.ID, "guild_id", i.GuildID)
+	if userID := interactionUserID(i); userID != "" {
+		logger = logger.With("user_id", userID)
+	}
+	return logger
+}
+
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// redactSecrets menyamarkan token dan secret sebelum ditulis ke log.
+func redactSecrets(s string) string {
+	if cfg != nil && cfg.BotToken != "" {
+		s = strings.ReplaceAll(s, cfg.BotToken, "[REDACTED]")
+	}
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}[REDACTED]${2}")
+	}
+	return s
+}
+
+func truncateForLog(s string) string {
+	if len(s) <= maxLoggedBodySize {
+		return s
+	}
+	return s[:maxLoggedBodySize] + "...(truncated)"
+}
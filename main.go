@@ -2,17 +2,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http" // Diperlukan untuk server keep-alive
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	// Hapus import pq jika tidak ada file lain yang butuh
 )
 
@@ -34,15 +35,394 @@ var (
 				},
 			},
 		},
+		{
+			Name:        "watch",
+			Description: "Menambahkan manhwa ke watchlist lewat link reader atau ID manga",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "link",
+					Description: "Link halaman manga/chapter di reader, atau ID manga",
+					Required:    true,
+				},
+			},
+		},
 		{
 			Name:        "watchlist",
-			Description: "Melihat daftar watchlist pribadimu",
+			Description: "Mengelola watchlist pribadimu",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "lihat",
+					Description: "Melihat daftar watchlist pribadimu",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "bulk",
+					Description: "Mengelola banyak series sekaligus (hapus, tandai terbaru, pindah rak)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "export",
+					Description: "Mengekspor watchlist ke file JSON, CSV atau MyAnimeList XML",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "format",
+							Description: "Format file export",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "JSON", Value: "json"},
+								{Name: "CSV", Value: "csv"},
+								{Name: "MyAnimeList XML (judul saja)", Value: "mal"},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "import",
+					Description: "Mengimpor watchlist dari file JSON, CSV atau MyAnimeList XML",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionAttachment,
+							Name:        "file",
+							Description: "File watchlist yang ingin diimpor",
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:                     "unfurl",
+			Description:              "Mengatur preview otomatis untuk link reader di server ini",
+			DefaultMemberPermissions: &manageGuildPermission,
+			DMPermission:             &dmPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "aktif",
+					Description: "Aktifkan atau nonaktifkan preview link",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:         "webhook",
+			Description:  "Mengatur webhook untuk menerima event chapter baru di luar Discord",
+			DMPermission: &dmPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "tambah",
+					Description: "Menambahkan URL webhook",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "URL https yang akan menerima POST JSON",
+							Required:    true,
+						},
+						webhookScopeOption,
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "lihat",
+					Description: "Melihat webhook yang terdaftar",
+					Options:     []*discordgo.ApplicationCommandOption{webhookScopeOption},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "hapus",
+					Description: "Menghapus webhook",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "id",
+							Description: "ID webhook (lihat /webhook lihat)",
+							Required:    true,
+						},
+						webhookScopeOption,
+					},
+				},
+			},
+		},
+		{
+			Name:        "feed",
+			Description: "Mendapatkan URL feed Atom pribadi untuk chapter baru di watchlist",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "lihat",
+					Description: "Menampilkan URL feed Anda",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "ganti",
+					Description: "Membuat URL feed baru dan menonaktifkan yang lama",
+				},
+			},
+		},
+		{
+			Name:        "token",
+			Description: "Mengelola token REST API pribadi",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "buat",
+					Description: "Membuat token API baru (token lama tidak berlaku lagi)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "cabut",
+					Description: "Mencabut token API Anda",
+				},
+			},
+		},
+		{
+			Name:        "link",
+			Description: "Menautkan akun reader agar progres baca tersinkron otomatis",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "kode",
+					Description: "Membuat kode sekali pakai untuk dimasukkan di situs reader",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "putus",
+					Description: "Memutus tautan akun reader",
+				},
+			},
+		},
+		{
+			Name:        "notifikasi",
+			Description: "Memilih saluran notifikasi chapter baru (Discord, email, push)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "lihat",
+					Description: "Melihat saluran notifikasi Anda",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "discord",
+					Description: "Mengaktifkan atau menonaktifkan mention di channel update",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "aktif",
+							Description: "Aktifkan mention Discord",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "email",
+					Description: "Mengatur alamat email notifikasi (kosongkan untuk menonaktifkan)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "alamat",
+							Description: "Alamat email; kode verifikasi akan dikirim ke alamat ini",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "verifikasi",
+					Description: "Mengonfirmasi alamat email dengan kode yang dikirim ke email Anda",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "kode",
+							Description: "Kode verifikasi dari email",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "push",
+					Description: "Mengatur URL push ntfy/Gotify (kosongkan untuk menonaktifkan)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "URL topik ntfy atau URL /message Gotify beserta token",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "digest",
+					Description: "Menerima ringkasan harian/mingguan alih-alih notifikasi tiap chapter",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "mode",
+							Description: "Cara pengiriman notifikasi chapter baru",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Langsung", Value: digestInstant},
+								{Name: "Harian", Value: digestDaily},
+								{Name: "Mingguan", Value: digestWeekly},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "jam",
+							Description: "Jam pengiriman ringkasan menurut zona waktu Anda (0-23)",
+							MinValue:    &minHourOfDay,
+							MaxValue:    23,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "hari",
+							Description: "Hari pengiriman ringkasan mingguan",
+							Choices:     weekdayChoices(),
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "zona",
+					Description: "Mengatur zona waktu Anda (kosongkan untuk kembali ke Asia/Jakarta)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "nama",
+							Description: "Nama zona waktu IANA, misalnya Asia/Makassar",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "senyap",
+					Description: "Menahan notifikasi selama jam tenang (kosongkan untuk menonaktifkan)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "mulai",
+							Description: "Jam mulai jam tenang (0-23)",
+							MinValue:    &minHourOfDay,
+							MaxValue:    23,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "selesai",
+							Description: "Jam berakhirnya jam tenang (0-23)",
+							MinValue:    &minHourOfDay,
+							MaxValue:    23,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "status",
+			Description: "Melihat riwayat pengecekan update dan series yang bermasalah",
+		},
+		{
+			Name:                     "admin",
+			Description:              "Perintah untuk operator bot",
+			DefaultMemberPermissions: &manageGuildPermission,
+			DMPermission:             &dmPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "check",
+					Description: "Paksa pengecekan update untuk semua series atau satu series",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "manga_id",
+							Description: "ID manga (kosongkan untuk semua series)",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "status",
+					Description: "Melihat status checker dan error terakhir",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "top",
+					Description: "Melihat series yang paling banyak di-watch",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "jumlah",
+							Description: "Jumlah series (maksimal 25)",
+							MinValue:    &minTopWatched,
+							MaxValue:    25,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "reset",
+					Description: "Mengatur ulang latest_known_chapter_id sebuah series",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "manga_id",
+							Description: "ID manga",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "chapter_id",
+							Description: "ID chapter (kosongkan untuk memakai chapter terbaru dari API)",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "test",
+					Description: "Mengirim test notifikasi ke channel update",
+				},
+			},
 		},
 	}
 	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"search":    searchCommandHandler,
-		"watchlist": watchlistCommandHandler,
+		"search":     searchCommandHandler,
+		"watch":      watchCommandHandler,
+		"watchlist":  watchlistCommandHandler,
+		"unfurl":     unfurlCommandHandler,
+		"admin":      adminCommandHandler,
+		"status":     statusCommandHandler,
+		"webhook":    webhookCommandHandler,
+		"feed":       feedCommandHandler,
+		"token":      tokenCommandHandler,
+		"link":       linkCommandHandler,
+		"notifikasi": notificationSettingsCommandHandler,
 	}
+
+	manageGuildPermission int64 = discordgo.PermissionManageGuild
+	dmPermission                = false
+	minTopWatched               = 1.0
+	minHourOfDay                = 0.0
+
+	webhookScopeOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "cakupan",
+		Description: "Webhook pribadi (default) atau webhook server",
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "Pribadi", Value: webhookOwnerUser},
+			{Name: "Server", Value: webhookOwnerGuild},
+		},
+	}
+
+	// appCtx dibatalkan saat shutdown; dipakai oleh pekerjaan di background
+	appCtx = context.Background()
+
+	// forceCheckRequests meminta checker goroutine menjalankan /admin check, agar ikut ditunggu saat shutdown
+	forceCheckRequests = make(chan checkRequest, 1)
 )
 
 type WatchlistItem struct {
@@ -51,6 +431,7 @@ type WatchlistItem struct {
 	MangaTitle                string
 	UserProgressChapterID     string
 	UserProgressChapterNumber float64
+	Shelf                     string
 }
 
 type Manga struct {
@@ -62,6 +443,7 @@ type Manga struct {
 
 type Chapter struct {
 	ID          string  `json:"chapter_id"`
+	MangaID     string  `json:"manga_id"`
 	Number      float64 `json:"chapter_number"`
 	ReleaseDate string  `json:"release_date"`
 }
@@ -84,76 +466,27 @@ type APIResponseChapter struct {
 	Data []Chapter `json:"data"`
 }
 
-func checkForUpdates(s *discordgo.Session) {
-	mangaToCheck, err := getUniqueMangaForUpdateCheck(db)
-	if err != nil {
-		log.Printf("Error getting unique manga for update check: %v", err)
+type APIResponseChapterDetail struct {
+	Data Chapter `json:"data"`
+}
+
+// registerCommands menyamakan daftar command di Discord dengan slice commands.
+// Bulk overwrite juga menghapus command lama yang sudah tidak ada di slice.
+func registerCommands(s *discordgo.Session) {
+	if cfg.SkipCommandRegistration {
+		slog.Info("Skipping command registration.")
 		return
 	}
 
-	for mangaID, knownChapterID := range mangaToCheck {
-		latestChapter, err := GetLatestChapter(mangaID)
-		if err != nil {
-			log.Printf("Failed to get latest chapter for mangaID %s: %v", mangaID, err)
-			continue
-		}
-
-		if latestChapter.ID != knownChapterID {
-			mangaDetails, err := GetMangaDetails(mangaID)
-			if err != nil {
-				log.Printf("Failed to get details for mangaID %s: %v", mangaID, err)
-				continue
-			}
-
-			log.Printf("New chapter found for %s: %s", mangaDetails.Title, latestChapter.ID)
-
-			users, err := getUsersForManga(db, mangaID)
-			if err != nil || len(users) == 0 {
-				continue
-			}
-
-			var mentions []string
-			for _, userID := range users {
-				mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
-			}
-			messageContent := strings.Join(mentions, " ")
-			chapterURL := fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, latestChapter.ID)
-
-			releaseTime, err := time.Parse(time.RFC3339, latestChapter.ReleaseDate)
-			var timestamp string
-			if err == nil {
-				timestamp = releaseTime.Format(time.RFC3339)
-			}
-			notificationEmbed := &discordgo.MessageEmbed{
-				Author: &discordgo.MessageEmbedAuthor{Name: "🔔 Chapter Baru Telah Rilis!"},
-				Title:  mangaDetails.Title,
-				URL:    chapterURL,
-				Color:  0xffa500,
-				Fields: []*discordgo.MessageEmbedField{
-					{Name: "Chapter Terbaru", Value: fmt.Sprintf("%.1f", latestChapter.Number), Inline: true},
-					{Name: "Tanggal Rilis", Value: releaseTime.Format("02 Jan 2006, 15:04 WIB"), Inline: true},
-				},
-				Footer:    &discordgo.MessageEmbedFooter{Text: "Eveeze Comic Bot", IconURL: "https://i.imgur.com/R4Ifj2p.png"},
-				Timestamp: timestamp,
-				Thumbnail: &discordgo.MessageEmbedThumbnail{URL: mangaDetails.CoverURL},
-			}
-
-			_, err = s.ChannelMessageSendComplex(cfg.UpdateChannelID, &discordgo.MessageSend{
-				Content: messageContent,
-				Embed:   notificationEmbed,
-			})
-			if err != nil {
-				log.Printf("Failed to send notification for %s: %v", mangaDetails.Title, err)
-				continue
-			}
-			
-			err = updateLatestKnownChapter(db, mangaID, latestChapter.ID)
-			if err != nil {
-				log.Printf("Failed to update latest known chapter for manga %s: %v", mangaID, err)
-			}
-		}
-		time.Sleep(3 * time.Second)
+	// Command guild langsung aktif, command global butuh waktu untuk tersebar
+	guildID := cfg.DevGuildID
+	slog.Info("Registering commands...", "count", len(commands), "guild_id", guildID)
+	registered, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, guildID, commands)
+	if err != nil {
+		slog.Error("Cannot register commands, keeping the existing ones", "guild_id", guildID, "error", err)
+		return
 	}
+	slog.Info("Commands registered.", "count", len(registered), "guild_id", guildID)
 }
 
 // -- Fungsi Main (Dengan Perubahan) --
@@ -161,69 +494,196 @@ func main() {
 	var err error
 
 	cfg = LoadConfig()
+	setupLogger(cfg)
 
 	// Panggil InitDB tanpa parameter
 	db, err = InitDB()
 	if err != nil {
-		log.Fatalf("Error initializing database: %v", err)
+		fatal("Error initializing database", "error", err)
 	}
-	defer db.Close()
 
 	s, err := discordgo.New("Bot " + cfg.BotToken)
 	if err != nil {
-		log.Fatalf("Invalid bot parameters: %v", err)
+		fatal("Invalid bot parameters", "error", err)
 	}
 
+	// appCtx dibuat sebelum server HTTP dan sesi Discord berjalan, lalu tidak diganti lagi,
+	// karena handler dan goroutine webhook membacanya
+	var cancel context.CancelFunc
+	appCtx, cancel = context.WithCancel(context.Background())
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Bot is alive and running!")
+	})
+	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/healthz", healthHandler(s, false))
+	http.HandleFunc("/readyz", healthHandler(s, true))
+	http.HandleFunc("/status", statusHTTPHandler)
+	http.HandleFunc("/feed/", feedHTTPHandler)
+	http.Handle("/api/", newRESTAPIHandler())
+	if cfg.ReaderCallbackSecret != "" {
+		http.HandleFunc("/callbacks/reader/link", readerLinkCallbackHandler(s))
+		http.HandleFunc("/callbacks/reader/progress", readerProgressCallbackHandler)
+	} else {
+		slog.Info("READER_CALLBACK_SECRET not set, reader progress sync disabled")
+	}
+	if cfg.WebhookSecret != "" {
+		http.HandleFunc("/webhooks/chapter", chapterWebhookHandler)
+	} else {
+		slog.Info("WEBHOOK_SECRET not set, chapter webhook disabled")
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	server := &http.Server{Addr: ":" + port}
 	go func() {
-		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, "Bot is alive and running!")
-		})
-		port := os.Getenv("PORT")
-		if port == "" {
-			port = "8080"
-		}
-		log.Printf("Starting keep-alive server on port %s", port)
-		if err := http.ListenAndServe(":"+port, nil); err != nil {
-			log.Printf("Keep-alive server failed to start: %v", err)
+		slog.Info("Starting keep-alive server", "port", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Keep-alive server failed to start", "error", err)
 		}
 	}()
 
+	notifiers = []Notifier{
+		&discordNotifier{session: s, channelID: cfg.UpdateChannelID},
+		&pushNotifier{client: webhookHTTPClient},
+	}
+	if cfg.SMTPHost != "" && cfg.SMTPFrom != "" {
+		notifiers = append(notifiers, newEmailNotifier(cfg))
+	} else {
+		slog.Info("SMTP_HOST or SMTP_FROM not set, email notifications disabled")
+	}
+
 	s.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		log.Printf("Logged in as: %v#%v", s.State.User.Username, s.State.User.Discriminator)
+		slog.Info("Logged in", "username", s.State.User.Username, "discriminator", s.State.User.Discriminator)
 	})
-	s.AddHandler(interactionHandler)
+	s.AddHandler(func(s *discordgo.Session, c *discordgo.Connect) { health.setConnected(true) })
+	s.AddHandler(func(s *discordgo.Session, d *discordgo.Disconnect) { health.setConnected(false) })
+	removeInteractionHandler := s.AddHandler(interactionHandler)
+	if cfg.UnfurlEnabled {
+		s.AddHandler(messageCreateHandler)
+		// Dibutuhkan untuk membaca link di pesan (preview link reader)
+		s.Identify.Intents |= discordgo.IntentsMessageContent
+	} else {
+		slog.Info("UNFURL_ENABLED not set, link previews disabled")
+	}
 
 	err = s.Open()
 	if err != nil {
-		log.Fatalf("Cannot open the session: %v", err)
+		fatal("Cannot open the session", "error", err)
 	}
-	defer s.Close()
 
-	log.Println("Adding commands...")
-	for _, v := range commands {
-		_, err := s.ApplicationCommandCreate(s.State.User.ID, "", v)
-		if err != nil {
-			log.Fatalf("Cannot create '%v' command: %v", v.Name, err)
+	registerCommands(s)
+
+	checkerDone := make(chan struct{})
+	ticker := time.NewTicker(schedulerTick)
+	go func() {
+		defer close(checkerDone)
+		slog.Info("Performing initial update check...")
+		checkForUpdates(appCtx, false)
+
+		for {
+			select {
+			case <-appCtx.Done():
+				return
+			case <-ticker.C:
+				slog.Debug("Checking for due updates...")
+				checkForUpdates(appCtx, false)
+			case req := <-forceCheckRequests:
+				if req.MangaID == "" {
+					checkForUpdates(appCtx, true)
+					continue
+				}
+				found, err := checkMangaForUpdate(req.MangaID, req.KnownChapterID)
+				req.Result <- checkResult{Found: found, Err: err}
+			}
 		}
-	}
-	log.Println("Commands added.")
+	}()
 
-	ticker := time.NewTicker(30 * time.Minute)
-	defer ticker.Stop()
+	// Anggota role series disinkronkan di background agar tidak menahan pengumuman chapter
+	roleSyncDone := make(chan struct{})
 	go func() {
-		log.Println("Performing initial update check...")
-		checkForUpdates(s)
+		defer close(roleSyncDone)
+		if cfg.SeriesRoleMinWatchers > 0 {
+			runSeriesRoleSync(appCtx, s, cfg.UpdateChannelID)
+		}
+	}()
 
-		for range ticker.C {
-			log.Println("Checking for updates...")
-			checkForUpdates(s)
+	// Purge baris watchlist yang sudah lewat masa undo dan kode /link yang kedaluwarsa,
+	// sekaligus mengirim digest dan pengingat tunda yang sudah jatuh tempo
+	purgeTicker := time.NewTicker(time.Minute)
+	purgeDone := make(chan struct{})
+	go func() {
+		defer close(purgeDone)
+		for {
+			select {
+			case <-appCtx.Done():
+				return
+			case <-purgeTicker.C:
+				purgeExpiredDeletes()
+				purgeExpiredLinkCodes()
+				purgeExpiredEmailVerifications()
+				purgeExpiredImports()
+				sendDueDigests(appCtx)
+				sendDueSnoozes(appCtx)
+			}
 		}
 	}()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-	log.Println("Bot is running. Press Ctrl+C to exit.")
+	slog.Info("Bot is running. Press Ctrl+C to exit.")
 	<-stop
 
-	log.Println("Gracefully shutting down.")
+	slog.Info("Gracefully shutting down.", "timeout", cfg.ShutdownTimeout)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer shutdownCancel()
+
+	// 1. Hentikan ticker dan tunggu checker menyelesaikan manga yang sedang diproses
+	ticker.Stop()
+	purgeTicker.Stop()
+	cancel()
+	select {
+	case <-checkerDone:
+		slog.Info("Update checker stopped.")
+	case <-shutdownCtx.Done():
+		slog.Warn("Timed out waiting for update checker to stop.")
+	}
+	select {
+	case <-purgeDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("Timed out waiting for purge and digest jobs to stop.")
+	}
+	select {
+	case <-roleSyncDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("Timed out waiting for series role sync to stop.")
+	}
+
+	// 2. Hentikan sumber event baru (interaksi dan server HTTP) sebelum menunggu pengiriman
+	// webhook, agar tidak ada webhookDeliveries.Add yang berjalan bersamaan dengan Wait
+	removeInteractionHandler()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shut down keep-alive server", "error", err)
+	}
+
+	webhooksDone := make(chan struct{})
+	go func() {
+		webhookDeliveries.Wait()
+		close(webhooksDone)
+	}()
+	select {
+	case <-webhooksDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("Timed out waiting for webhook deliveries.")
+	}
+
+	// 3. Sesi Discord, lalu database
+	if err := s.Close(); err != nil {
+		slog.Error("Failed to close Discord session", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+	slog.Info("Shutdown complete.")
 }
\ No newline at end of file
@@ -0,0 +1,106 @@
+// metrics.go (Metrik Prometheus untuk endpoint /metrics)
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	apiRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "eveeze_api_request_duration_seconds",
+		Help:    "Latency of source API requests by endpoint and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint", "status"})
+
+	apiRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_api_requests_total",
+		Help: "Source API requests by endpoint and status.",
+	}, []string{"endpoint", "status"})
+
+	updateCheckDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "eveeze_update_check_duration_seconds",
+		Help:    "Duration of a full update check run.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800},
+	})
+
+	chaptersDetected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "eveeze_chapters_detected_total",
+		Help: "Number of new chapters detected by the update checker.",
+	})
+
+	notificationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_notifications_total",
+		Help: "Notifications by backend (discord, email or push) and result (sent or failed).",
+	}, []string{"backend", "result"})
+
+	interactionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_interactions_total",
+		Help: "Discord interactions handled by type and command or action.",
+	}, []string{"type", "name"})
+
+	webhookEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_webhook_events_total",
+		Help: "Inbound chapter webhook events by result.",
+	}, []string{"result"})
+
+	webhookDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_webhook_deliveries_total",
+		Help: "Outbound webhook deliveries by result (delivered, failed or circuit_open).",
+	}, []string{"result"})
+
+	restAPIRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_rest_api_requests_total",
+		Help: "REST API requests by route pattern and status.",
+	}, []string{"route", "status"})
+
+	readerCallbacksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eveeze_reader_callbacks_total",
+		Help: "Reader site callbacks by endpoint (link or progress) and result.",
+	}, []string{"endpoint", "result"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "eveeze_db_query_duration_seconds",
+		Help:    "Latency of database queries by query name.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"query"})
+)
+
+// Prefix custom ID komponen; ID dinamis di belakangnya tidak boleh jadi label.
+var componentActions = []string{
+	"add_watchlist", "show_unread", "mark_read", "mark_latest", "delete_watchlist",
+	"confirm_delete", "undo_delete", "watchlist_page", "import_select", "import_run",
+	"import_cancel", "unfurl_watch", "unfurl_read", "bulk_select", "bulk_page",
+	"bulk_delete", "bulk_latest", "bulk_shelf", "search_replacement", "unavailable_remove",
+	"notify_read", "notify_snooze", "notify_unsub", "page",
+}
+
+func componentAction(customID string) string {
+	for _, action := range componentActions {
+		if customID == action || strings.HasPrefix(customID, action+"_") {
+			return action
+		}
+	}
+	return "unknown"
+}
+
+func observeAPIRequest(endpoint string, status int, start time.Time) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	apiRequestsTotal.WithLabelValues(endpoint, label).Inc()
+	apiRequestDuration.WithLabelValues(endpoint, label).Observe(time.Since(start).Seconds())
+}
+
+// observeDBQuery dipakai sebagai: defer observeDBQuery("nama_query")()
+func observeDBQuery(query string) func() {
+	start := time.Now()
+	return func() {
+		dbQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+	}
+}
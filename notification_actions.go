@@ -0,0 +1,130 @@
+// notification_actions.go (Tombol di notifikasi chapter baru: tandai dibaca, tunda, berhenti mengikuti)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const snoozeDuration = 24 * time.Hour
+
+func createNotificationActionsRow(mangaID, chapterID string) discordgo.ActionsRow {
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "✅ Tandai Dibaca", Style: discordgo.SuccessButton, CustomID: fmt.Sprintf("notify_read_%s:%s", mangaID, chapterID)},
+			discordgo.Button{Label: "⏰ Tunda 1 Hari", Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("notify_snooze_%s:%s", mangaID, chapterID)},
+			discordgo.Button{Label: "🔕 Berhenti Mengikuti", Style: discordgo.DangerButton, CustomID: fmt.Sprintf("notify_unsub_%s", mangaID)},
+		},
+	}
+}
+
+// parseNotificationCustomID membaca custom ID "notify_<aksi>_<mangaID>[:<chapterID>]".
+// ID manga dan chapter bisa mengandung "_", jadi keduanya dipisah dengan ":".
+func parseNotificationCustomID(customID string) (action, mangaID, chapterID string, ok bool) {
+	for _, a := range []string{"read", "snooze", "unsub"} {
+		rest, found := strings.CutPrefix(customID, "notify_"+a+"_")
+		if !found {
+			continue
+		}
+		mangaID, chapterID, _ = strings.Cut(rest, ":")
+		return a, mangaID, chapterID, mangaID != ""
+	}
+	return "", "", "", false
+}
+
+// Pesan notifikasi dibagikan ke semua watcher, jadi setiap tombol membalas ephemeral
+// dan hanya berlaku untuk user yang benar-benar mengikuti series tersebut.
+func notificationComponentHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		return
+	}
+	userID := interactionUserID(i)
+
+	action, mangaID, chapterID, ok := parseNotificationCustomID(customID)
+	if !ok {
+		return
+	}
+	item, err := GetWatchlistItem(db, userID, mangaID)
+	if err != nil {
+		editInteractionContent(s, i, "ℹ️ Tombol ini hanya untuk watcher series ini. Gunakan `/watch` untuk mengikutinya.")
+		return
+	}
+
+	if action == "unsub" {
+		s.InteractionResponseEdit(i.Interaction, createDeleteConfirmMessage(item))
+		return
+	}
+	if chapterID == "" {
+		return
+	}
+	chapter, err := GetSeenChapter(db, mangaID, chapterID)
+	if err != nil {
+		logFor(i).Error("Failed to get notified chapter", "manga_id", mangaID, "chapter_id", chapterID, "error", err)
+		editInteractionContent(s, i, "❌ Chapter ini tidak ditemukan.")
+		return
+	}
+	if chapter.Number <= item.UserProgressChapterNumber {
+		editInteractionContent(s, i, fmt.Sprintf("✅ Anda sudah membaca **%s** sampai chapter **%.1f**.", item.MangaTitle, item.UserProgressChapterNumber))
+		return
+	}
+
+	switch action {
+	case "read":
+		if err := UpdateUserProgress(db, userID, mangaID, chapter.ID, chapter.Number); err != nil {
+			logFor(i).Error("Failed to update user progress", "manga_id", mangaID, "error", err)
+			editInteractionContent(s, i, "Gagal memperbarui progres.")
+			return
+		}
+		editInteractionContent(s, i, fmt.Sprintf("✅ Progres **%s** diperbarui ke chapter **%.1f**.", item.MangaTitle, chapter.Number))
+	case "snooze":
+		remindAt := time.Now().Add(snoozeDuration)
+		if err := SnoozeChapter(db, userID, mangaID, chapter.ID, remindAt); err != nil {
+			logFor(i).Error("Failed to snooze chapter", "manga_id", mangaID, "error", err)
+			editInteractionContent(s, i, "Gagal menunda notifikasi.")
+			return
+		}
+		editInteractionContent(s, i, fmt.Sprintf("⏰ Anda akan diingatkan lagi tentang **%s** chapter **%.1f** <t:%d:R>.",
+			item.MangaTitle, chapter.Number, remindAt.Unix()))
+	}
+}
+
+// sendDueSnoozes mengirim ulang notifikasi yang ditunda. Pengingat untuk series yang sudah
+// dihapus atau chapter yang sudah dibaca dibuang; pengingat di jam tenang menunggu jam tenang berakhir.
+func sendDueSnoozes(ctx context.Context) {
+	snoozes, err := GetDueSnoozes(db, time.Now())
+	if err != nil {
+		slog.Error("Failed to get due snoozes", "error", err)
+		return
+	}
+	for _, z := range snoozes {
+		if ctx.Err() != nil {
+			return
+		}
+		if z.Watching && z.Chapter.Number > z.UserProgressChapterNumber {
+			prefs, err := GetNotificationPrefs(db, z.UserID)
+			if err != nil {
+				slog.Error("Failed to get notification prefs", "user_id", z.UserID, "error", err)
+				continue
+			}
+			if prefs.InQuietHours(time.Now()) {
+				continue
+			}
+			n := Notification{Kind: NotificationNewChapter, Manga: &z.Manga, Chapter: &z.Chapter, Reminder: true}
+			if _, err := deliverNotification(ctx, n, []NotificationPrefs{prefs}); err != nil {
+				slog.Warn("Failed to send snooze reminder", "user_id", z.UserID, "chapter_id", z.Chapter.ID, "error", err)
+			}
+		}
+		if err := DeleteSnooze(db, z.UserID, z.Chapter.ID); err != nil {
+			slog.Error("Failed to delete snooze", "user_id", z.UserID, "chapter_id", z.Chapter.ID, "error", err)
+		}
+	}
+}
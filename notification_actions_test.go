@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestNotificationCustomIDRoundTrip(t *testing.T) {
+	const mangaID, chapterID = "one_piece", "chapter_1100_en"
+	row := createNotificationActionsRow(mangaID, chapterID)
+
+	want := []struct{ action, chapterID string }{{"read", chapterID}, {"snooze", chapterID}, {"unsub", ""}}
+	if len(row.Components) != len(want) {
+		t.Fatalf("got %d buttons, want %d", len(row.Components), len(want))
+	}
+	for idx, component := range row.Components {
+		customID := component.(discordgo.Button).CustomID
+		action, gotManga, gotChapter, ok := parseNotificationCustomID(customID)
+		if !ok || action != want[idx].action || gotManga != mangaID || gotChapter != want[idx].chapterID {
+			t.Errorf("parseNotificationCustomID(%q) = %q, %q, %q, %v", customID, action, gotManga, gotChapter, ok)
+		}
+		if got := componentAction(customID); got != "notify_"+want[idx].action {
+			t.Errorf("componentAction(%q) = %q", customID, got)
+		}
+	}
+
+	if _, _, _, ok := parseNotificationCustomID("notify_other_x"); ok {
+		t.Error("parseNotificationCustomID accepted an unknown action")
+	}
+}
@@ -0,0 +1,303 @@
+// notifier.go (Abstraksi saluran notifikasi dan perintah /notifikasi)
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type NotificationKind int
+
+const (
+	NotificationNewChapter NotificationKind = iota
+	NotificationSeriesUnavailable
+	NotificationDigest
+)
+
+// Notification adalah pesan untuk watcher sebuah series. Chapter nil untuk NotificationSeriesUnavailable;
+// NotificationDigest hanya mengisi Digest dan selalu untuk satu user.
+type Notification struct {
+	Kind    NotificationKind
+	Manga   *Manga
+	Chapter *Chapter
+	Digest  []DigestEntry
+	// Reminder menandai pengingat ulang dari tombol tunda; selalu untuk satu user
+	Reminder bool
+}
+
+// Subject dan Body dipakai oleh backend berbasis teks (email, push).
+func (n Notification) Subject() string {
+	switch n.Kind {
+	case NotificationSeriesUnavailable:
+		return fmt.Sprintf("Series tidak tersedia: %s", n.Manga.Title)
+	case NotificationDigest:
+		return fmt.Sprintf("Ringkasan: %d chapter baru", len(n.Digest))
+	}
+	if n.Reminder {
+		return fmt.Sprintf("Pengingat: %s — Chapter %.1f", n.Manga.Title, n.Chapter.Number)
+	}
+	return fmt.Sprintf("%s — Chapter %.1f", n.Manga.Title, n.Chapter.Number)
+}
+
+func (n Notification) Body() string {
+	if n.Kind == NotificationSeriesUnavailable {
+		return fmt.Sprintf("%s tidak lagi ditemukan di sumber (mungkin dihapus, diganti nama, atau dipindah). "+
+			"Buka /watchlist di Discord untuk menghapusnya atau mencari penggantinya.", n.Manga.Title)
+	}
+	if n.Kind == NotificationDigest {
+		var body strings.Builder
+		body.WriteString("Chapter baru dari watchlist Anda:\n")
+		for idx, e := range n.Digest {
+			if idx == 0 || n.Digest[idx-1].MangaID != e.MangaID {
+				fmt.Fprintf(&body, "\n%s\n", e.MangaTitle)
+			}
+			fmt.Fprintf(&body, "- Chapter %.1f: %s/chapter/%s\n", e.ChapterNumber, cfg.ReaderBaseURL, e.ChapterID)
+		}
+		return body.String()
+	}
+	return fmt.Sprintf("Chapter %.1f dari %s telah rilis!\nBaca sekarang: %s", n.Chapter.Number, n.Manga.Title, n.URL())
+}
+
+func (n Notification) URL() string {
+	if n.Chapter == nil {
+		return cfg.ReaderBaseURL
+	}
+	return fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, n.Chapter.ID)
+}
+
+// Notifier adalah satu saluran pengiriman notifikasi. Backend hanya menerima recipient
+// yang mengaktifkan saluran tersebut (lihat NotificationPrefs.Enabled).
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification, recipients []NotificationPrefs) error
+}
+
+// notifiers diisi di main sesuai konfigurasi
+var notifiers []Notifier
+
+func (p NotificationPrefs) Enabled(channel string) bool {
+	switch channel {
+	case "discord":
+		return p.Discord
+	case "email":
+		return p.Email != ""
+	case "push":
+		return p.PushURL != ""
+	}
+	return false
+}
+
+// notifyWatchers mengirim notifikasi ke semua watcher manga lewat saluran pilihan masing-masing.
+// Chapter baru untuk user mode digest atau yang sedang di jam tenang hanya dimasukkan ke antrean
+// digest; antrean user mode langsung dikirim sendDueDigests saat jam tenang berakhir.
+// watched bernilai false hanya jika manga tidak punya watcher, meskipun tidak ada yang terkirim
+// (misalnya semua saluran watcher nonaktif). Error hanya dikembalikan jika semua saluran yang
+// punya recipient gagal, agar saluran yang berhasil tidak menerima duplikat saat dicoba ulang.
+func notifyWatchers(ctx context.Context, n Notification) (watched bool, err error) {
+	prefs, err := GetNotificationPrefsForManga(db, n.Manga.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(prefs) == 0 {
+		return false, nil
+	}
+
+	if n.Kind == NotificationNewChapter {
+		now := time.Now()
+		var immediate []NotificationPrefs
+		for _, p := range prefs {
+			if p.DigestMode == "" && !p.InQuietHours(now) {
+				immediate = append(immediate, p)
+				continue
+			}
+			if err := QueueDigestChapter(db, p.UserID, n.Manga.ID, n.Chapter); err != nil {
+				return false, err
+			}
+		}
+		if len(immediate) == 0 {
+			return true, nil
+		}
+		prefs = immediate
+	}
+
+	if _, err := deliverNotification(ctx, n, prefs); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deliverNotification mengirim n ke setiap backend dengan recipient yang mengaktifkan saluran tersebut.
+func deliverNotification(ctx context.Context, n Notification, prefs []NotificationPrefs) (bool, error) {
+	var errs []error
+	attempted := 0
+	for _, notifier := range notifiers {
+		var recipients []NotificationPrefs
+		for _, p := range prefs {
+			if p.Enabled(notifier.Name()) {
+				recipients = append(recipients, p)
+			}
+		}
+		if len(recipients) == 0 {
+			continue
+		}
+		attempted++
+		if err := notifier.Notify(ctx, n, recipients); err != nil {
+			slog.Error("Failed to send notification", "backend", notifier.Name(), "kind", n.Kind, "error", err)
+			notificationsTotal.WithLabelValues(notifier.Name(), "failed").Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+			continue
+		}
+		notificationsTotal.WithLabelValues(notifier.Name(), "sent").Inc()
+	}
+	if attempted > 0 && len(errs) == attempted {
+		return false, errors.Join(errs...)
+	}
+	return attempted > 0, nil
+}
+
+func notificationSettingsCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	respond := func(msg string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+		})
+	}
+	prefs, err := GetNotificationPrefs(db, interactionUserID(i))
+	if err != nil {
+		logFor(i).Error("Failed to get notification prefs", "error", err)
+		respond("Gagal membaca pengaturan notifikasi.")
+		return
+	}
+
+	sub := i.ApplicationCommandData().Options[0]
+	var value string
+	if len(sub.Options) > 0 {
+		value = strings.TrimSpace(fmt.Sprint(sub.Options[0].Value))
+	}
+	switch sub.Name {
+	case "lihat":
+		respond(describeNotificationPrefs(prefs))
+		return
+	case "discord":
+		prefs.Discord = sub.Options[0].BoolValue()
+	case "email":
+		if !hasNotifier("email") {
+			respond("❌ Notifikasi email belum diaktifkan oleh operator bot.")
+			return
+		}
+		if value != "" {
+			addr, err := mail.ParseAddress(value)
+			if err != nil {
+				respond("❌ Alamat email tidak valid.")
+				return
+			}
+			// Alamat baru baru dipakai setelah kodenya dikonfirmasi
+			startEmailVerification(s, i, addr.Address)
+			return
+		}
+		prefs.Email = ""
+		if err := DeleteEmailVerification(db, prefs.UserID); err != nil {
+			logFor(i).Error("Failed to delete email verification", "error", err)
+		}
+	case "verifikasi":
+		address, userMsg, err := confirmEmailVerification(prefs.UserID, value, time.Now())
+		if err != nil {
+			logFor(i).Error("Failed to confirm email verification", "error", err)
+			respond("Gagal memeriksa kode verifikasi.")
+			return
+		}
+		if userMsg != "" {
+			respond(userMsg)
+			return
+		}
+		prefs.Email = address
+	case "push":
+		if value != "" {
+			if err := validateWebhookURL(value); err != nil {
+				respond("❌ " + err.Error() + ".")
+				return
+			}
+		}
+		prefs.PushURL = value
+	case "digest":
+		previousMode := prefs.DigestMode
+		for _, opt := range sub.Options {
+			switch opt.Name {
+			case "mode":
+				prefs.DigestMode = opt.StringValue()
+				if prefs.DigestMode == digestInstant {
+					prefs.DigestMode = ""
+				}
+			case "jam":
+				prefs.DigestHour = int(opt.IntValue())
+			case "hari":
+				prefs.DigestWeekday = int(opt.IntValue())
+			}
+		}
+		// Digest pertama dikirim pada jadwal berikutnya, bukan langsung saat diaktifkan
+		if previousMode == "" && prefs.DigestMode != "" {
+			prefs.LastDigestAt = time.Now()
+		}
+	case "zona":
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil || value == "Local" {
+				respond("❌ Zona waktu tidak dikenal. Gunakan nama IANA seperti `Asia/Jakarta`, `Asia/Makassar`, atau `Europe/London`.")
+				return
+			}
+		}
+		prefs.Timezone = value
+	case "senyap":
+		prefs.QuietStart, prefs.QuietEnd = -1, -1
+		for _, opt := range sub.Options {
+			switch opt.Name {
+			case "mulai":
+				prefs.QuietStart = int(opt.IntValue())
+			case "selesai":
+				prefs.QuietEnd = int(opt.IntValue())
+			}
+		}
+		if (prefs.QuietStart < 0) != (prefs.QuietEnd < 0) || (prefs.QuietStart >= 0 && prefs.QuietStart == prefs.QuietEnd) {
+			respond("❌ Isi jam `mulai` dan `selesai` yang berbeda, atau kosongkan keduanya untuk menonaktifkan jam tenang.")
+			return
+		}
+	}
+
+	if err := SetNotificationPrefs(db, prefs); err != nil {
+		logFor(i).Error("Failed to save notification prefs", "error", err)
+		respond("Gagal menyimpan pengaturan notifikasi.")
+		return
+	}
+	respond("✅ Pengaturan disimpan.\n\n" + describeNotificationPrefs(prefs))
+}
+
+func hasNotifier(name string) bool {
+	for _, notifier := range notifiers {
+		if notifier.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func describeNotificationPrefs(prefs NotificationPrefs) string {
+	state := func(enabled bool, detail string) string {
+		if !enabled {
+			return "❌ nonaktif"
+		}
+		if detail == "" {
+			return "✅ aktif"
+		}
+		return "✅ " + detail
+	}
+	return fmt.Sprintf("🔔 **Saluran Notifikasi**\n• Discord (mention di channel update): %s\n• Email: %s\n• Push (ntfy/Gotify): %s\n\n📬 Pengiriman: %s",
+		state(prefs.Discord, ""), state(prefs.Email != "", prefs.Email), state(prefs.PushURL != "", truncateTitle(prefs.PushURL, 60)),
+		describeDigestMode(prefs)) +
+		fmt.Sprintf("\n🕒 Zona waktu: %s\n🌙 Jam tenang: %s", prefs.TimezoneName(), describeQuietHours(prefs))
+}
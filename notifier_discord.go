@@ -0,0 +1,156 @@
+// notifier_discord.go (Backend notifikasi Discord: mention di channel update)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type discordNotifier struct {
+	session   *discordgo.Session
+	channelID string
+}
+
+func (d *discordNotifier) Name() string { return "discord" }
+
+func (d *discordNotifier) Notify(ctx context.Context, n Notification, recipients []NotificationPrefs) error {
+	if n.Kind == NotificationDigest {
+		return d.sendDigest(ctx, n, recipients)
+	}
+	var roleUserIDs, directUserIDs []string
+	for _, r := range recipients {
+		if r.usesSeriesRole() {
+			roleUserIDs = append(roleUserIDs, r.UserID)
+		} else {
+			directUserIDs = append(directUserIDs, r.UserID)
+		}
+	}
+	var mentions []string
+	if !n.Reminder {
+		mentions = seriesRoleMentions(n.Manga, roleUserIDs)
+	}
+	if mentions == nil {
+		mentions = userMentions(roleUserIDs)
+	}
+	mentions = append(mentions, userMentions(directUserIDs)...)
+
+	msg := &discordgo.MessageSend{}
+	switch n.Kind {
+	case NotificationNewChapter:
+		msg.Embed = createNotificationEmbed(n.Manga, n.Chapter)
+		if n.Reminder {
+			msg.Embed.Author.Name = "⏰ Pengingat Chapter"
+		}
+		msg.Components = []discordgo.MessageComponent{createNotificationActionsRow(n.Manga.ID, n.Chapter.ID)}
+	case NotificationSeriesUnavailable:
+		msg.Embed = createUnavailableEmbed(n.Manga.Title)
+		msg.Components = []discordgo.MessageComponent{createUnavailableActionsRow(n.Manga.ID)}
+	}
+
+	// Embed ikut pesan pertama; sisa mention dikirim menyusul. Kegagalan setelah pesan pertama
+	// hanya dicatat agar percobaan ulang tidak menggandakan pesan yang sudah terkirim.
+	chunks := chunkMentions(mentions, maxMessageContentLength)
+	for idx, chunk := range chunks {
+		if idx > 0 {
+			msg = &discordgo.MessageSend{}
+		}
+		msg.Content = chunk
+		if _, err := d.session.ChannelMessageSendComplex(d.channelID, msg, discordgo.WithContext(ctx)); err != nil {
+			if idx == 0 {
+				return err
+			}
+			slog.Error("Failed to send mention chunk", "manga_id", n.Manga.ID, "chunk", idx+1, "chunks", len(chunks), "error", err)
+		}
+	}
+	return nil
+}
+
+// usesSeriesRole menentukan siapa yang boleh masuk role series. User dengan jam tenang atau mode
+// digest tidak selalu menerima notifikasi langsung, jadi mereka selalu di-mention per user; dengan
+// begitu anggota role hanya berubah saat user mengubah pengaturan, bukan setiap jam tenang.
+func (p NotificationPrefs) usesSeriesRole() bool {
+	return p.DigestMode == "" && !p.HasQuietHours()
+}
+
+func userMentions(userIDs []string) []string {
+	mentions := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+	}
+	return mentions
+}
+
+// Batas panjang content pesan Discord
+const maxMessageContentLength = 2000
+
+// chunkMentions menggabungkan mention menjadi beberapa content yang masing-masing tidak melebihi limit.
+func chunkMentions(mentions []string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, mention := range mentions {
+		if current.Len() > 0 && current.Len()+1+len(mention) > limit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(mention)
+	}
+	if current.Len() > 0 || len(chunks) == 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// Digest bersifat pribadi sehingga dikirim lewat DM, bukan mention di channel update.
+func (d *discordNotifier) sendDigest(ctx context.Context, n Notification, recipients []NotificationPrefs) error {
+	for _, r := range recipients {
+		channel, err := d.session.UserChannelCreate(r.UserID, discordgo.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		msg := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{createDigestEmbed(n.Digest)}}
+		if _, err := d.session.ChannelMessageSendComplex(channel.ID, msg, discordgo.WithContext(ctx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createNotificationEmbed(manga *Manga, chapter *Chapter) *discordgo.MessageEmbed {
+	chapterURL := fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, chapter.ID)
+
+	releaseTime, err := time.Parse(time.RFC3339, chapter.ReleaseDate)
+	var timestamp string
+	if err == nil {
+		timestamp = releaseTime.Format(time.RFC3339)
+	}
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{Name: "🔔 Chapter Baru Telah Rilis!"},
+		Title:  manga.Title,
+		URL:    chapterURL,
+		Color:  0xffa500,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Chapter Terbaru", Value: fmt.Sprintf("%.1f", chapter.Number), Inline: true},
+			{Name: "Tanggal Rilis", Value: discordTimestamp(releaseTime), Inline: true},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "Eveeze Comic Bot", IconURL: "https://i.imgur.com/R4Ifj2p.png"},
+		Timestamp: timestamp,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{URL: manga.CoverURL},
+	}
+}
+
+// discordTimestamp ditampilkan Discord sesuai zona waktu masing-masing pembaca,
+// karena embed di channel update dibaca banyak user sekaligus.
+func discordTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("<t:%d:f>", t.Unix())
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		mentions []string
+		limit    int
+		want     []string
+	}{
+		{"empty", nil, 20, []string{""}},
+		{"single", []string{"<@1>"}, 20, []string{"<@1>"}},
+		{"fits exactly", []string{"<@1>", "<@2>", "<@3>"}, 14, []string{"<@1> <@2> <@3>"}},
+		{"splits on overflow", []string{"<@1>", "<@2>", "<@3>"}, 13, []string{"<@1> <@2>", "<@3>"}},
+		{"one per chunk", []string{"<@1>", "<@2>", "<@3>"}, 4, []string{"<@1>", "<@2>", "<@3>"}},
+		{"role first", []string{"<@&99>", "<@1>", "<@2>"}, 12, []string{"<@&99> <@1>", "<@2>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkMentions(tt.mentions, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkMentions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkMentionsDiscordLimit(t *testing.T) {
+	mentions := make([]string, 500)
+	for idx := range mentions {
+		mentions[idx] = "<@123456789012345678>"
+	}
+	chunks := chunkMentions(mentions, maxMessageContentLength)
+	total := 0
+	for idx, chunk := range chunks {
+		if len(chunk) > maxMessageContentLength {
+			t.Errorf("chunk %d has %d characters, limit %d", idx, len(chunk), maxMessageContentLength)
+		}
+		total += len(strings.Fields(chunk))
+	}
+	if total != len(mentions) {
+		t.Errorf("chunks contain %d mentions, want %d", total, len(mentions))
+	}
+}
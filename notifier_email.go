@@ -0,0 +1,117 @@
+// notifier_email.go (Backend notifikasi email lewat SMTP)
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Batas waktu satu pengiriman email (koneksi sampai QUIT), agar server SMTP yang menggantung
+// tidak menahan checker
+const smtpTimeout = 30 * time.Second
+
+type emailNotifier struct {
+	host string
+	addr string
+	from string
+	auth smtp.Auth // nil untuk SMTP tanpa autentikasi (misalnya sink lokal)
+}
+
+func newEmailNotifier(cfg *Config) *emailNotifier {
+	n := &emailNotifier{host: cfg.SMTPHost, addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort), from: cfg.SMTPFrom}
+	if cfg.SMTPUsername != "" {
+		n.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return n
+}
+
+func (e *emailNotifier) Name() string { return "email" }
+
+// Notify mengirim satu email per recipient agar alamat user lain tidak terlihat.
+func (e *emailNotifier) Notify(ctx context.Context, n Notification, recipients []NotificationPrefs) error {
+	var failed []string
+	for _, r := range recipients {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := e.send(ctx, r.Email, n.Subject(), n.Body()); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.UserID, err))
+		}
+	}
+	if len(failed) == len(recipients) {
+		return fmt.Errorf("all emails failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// send setara smtp.SendMail, tetapi dengan timeout dan ikut berhenti saat ctx dibatalkan.
+func (e *emailNotifier) send(ctx context.Context, to, subject, body string) error {
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if e.auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(e.auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(e.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(e.buildMessage(to, subject, body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (e *emailNotifier) buildMessage(to, subject, body string) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	msg.WriteString("\r\n\r\n-- \r\nEveeze Comic Bot. Ubah saluran notifikasi dengan /notifikasi di Discord.\r\n")
+	return []byte(msg.String())
+}
@@ -0,0 +1,65 @@
+// notifier_push.go (Backend notifikasi push HTTP gaya ntfy/Gotify)
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type pushNotifier struct {
+	client *http.Client
+}
+
+func (p *pushNotifier) Name() string { return "push" }
+
+func (p *pushNotifier) Notify(ctx context.Context, n Notification, recipients []NotificationPrefs) error {
+	var failed []string
+	for _, r := range recipients {
+		if err := p.push(ctx, r.PushURL, n); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.UserID, err))
+		}
+	}
+	if len(failed) == len(recipients) {
+		return fmt.Errorf("all pushes failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// push memakai format Gotify (JSON) untuk URL .../message, selain itu format ntfy
+// (body teks dengan header Title/Click/Tags).
+func (p *pushNotifier) push(ctx context.Context, url string, n Notification) error {
+	var req *http.Request
+	var err error
+	if strings.HasSuffix(strings.SplitN(url, "?", 2)[0], "/message") {
+		body, _ := json.Marshal(map[string]any{"title": n.Subject(), "message": n.Body(), "priority": 5})
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(n.Body()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Title", mime.QEncoding.Encode("utf-8", n.Subject()))
+		req.Header.Set("Click", n.URL())
+		req.Header.Set("Tags", "books")
+	}
+	req.Header.Set("User-Agent", "EveezeComicBot-Push/1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("push returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
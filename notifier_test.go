@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// recordingNotifier mencatat recipient setiap pengiriman; err != nil membuat pengiriman gagal.
+type recordingNotifier struct {
+	name       string
+	err        error
+	recipients []string
+}
+
+func (r *recordingNotifier) Name() string { return r.name }
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification, recipients []NotificationPrefs) error {
+	for _, p := range recipients {
+		r.recipients = append(r.recipients, p.UserID)
+	}
+	sort.Strings(r.recipients)
+	return r.err
+}
+
+func TestNotifyWatchers(t *testing.T) {
+	newTestDB(t)
+	discord := &recordingNotifier{name: "discord"}
+	email := &recordingNotifier{name: "email"}
+	previous := notifiers
+	notifiers = []Notifier{discord, email}
+	t.Cleanup(func() { notifiers = previous })
+	reset := func() {
+		discord.recipients, discord.err = nil, nil
+		email.recipients, email.err = nil, nil
+	}
+
+	manga := &Manga{ID: "m1", Title: "Manga"}
+	chapter := &Chapter{ID: "c5", MangaID: "m1", Number: 5}
+	quietFrom := time.Now().In(defaultLocation).Hour()
+	users := []NotificationPrefs{
+		{UserID: "discord-user", Discord: true, QuietStart: -1, QuietEnd: -1},
+		{UserID: "email-user", Email: "reader@example.com", QuietStart: -1, QuietEnd: -1},
+		{UserID: "both-user", Discord: true, Email: "both@example.com", QuietStart: -1, QuietEnd: -1},
+		{UserID: "digest-user", Discord: true, DigestMode: digestDaily, DigestHour: 8, QuietStart: -1, QuietEnd: -1},
+		{UserID: "quiet-user", Discord: true, QuietStart: quietFrom, QuietEnd: (quietFrom + 2) % 24},
+	}
+	for _, p := range users {
+		if err := AddToWatchlist(db, WatchlistItem{MangaID: manga.ID, UserID: p.UserID, MangaTitle: manga.Title, UserProgressChapterID: "c4", UserProgressChapterNumber: 4}); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetNotificationPrefs(db, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("new chapter goes to enabled channels, digest and quiet users are queued", func(t *testing.T) {
+		reset()
+		watched, err := notifyWatchers(context.Background(), Notification{Kind: NotificationNewChapter, Manga: manga, Chapter: chapter})
+		if err != nil || !watched {
+			t.Fatalf("notifyWatchers() = %v, %v", watched, err)
+		}
+		if want := []string{"both-user", "discord-user"}; !reflect.DeepEqual(discord.recipients, want) {
+			t.Errorf("discord recipients = %v, want %v", discord.recipients, want)
+		}
+		if want := []string{"both-user", "email-user"}; !reflect.DeepEqual(email.recipients, want) {
+			t.Errorf("email recipients = %v, want %v", email.recipients, want)
+		}
+		for _, userID := range []string{"digest-user", "quiet-user"} {
+			entries, err := GetPendingDigest(db, userID, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].ChapterID != chapter.ID {
+				t.Errorf("digest queue for %s = %+v, want chapter %s", userID, entries, chapter.ID)
+			}
+		}
+	})
+
+	t.Run("series notices skip the digest queue", func(t *testing.T) {
+		reset()
+		if _, err := notifyWatchers(context.Background(), Notification{Kind: NotificationSeriesUnavailable, Manga: manga}); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"both-user", "digest-user", "discord-user", "quiet-user"}; !reflect.DeepEqual(discord.recipients, want) {
+			t.Errorf("discord recipients = %v, want %v", discord.recipients, want)
+		}
+	})
+
+	t.Run("one failing backend is not an error", func(t *testing.T) {
+		reset()
+		email.err = errors.New("smtp down")
+		if _, err := notifyWatchers(context.Background(), Notification{Kind: NotificationNewChapter, Manga: manga, Chapter: chapter}); err != nil {
+			t.Errorf("notifyWatchers() error = %v, want nil while discord succeeds", err)
+		}
+	})
+
+	t.Run("all backends failing is an error", func(t *testing.T) {
+		reset()
+		discord.err = errors.New("discord down")
+		email.err = errors.New("smtp down")
+		if _, err := notifyWatchers(context.Background(), Notification{Kind: NotificationNewChapter, Manga: manga, Chapter: chapter}); err == nil {
+			t.Error("notifyWatchers() error = nil, want error when every backend fails")
+		}
+	})
+
+	t.Run("series without watchers", func(t *testing.T) {
+		reset()
+		watched, err := notifyWatchers(context.Background(), Notification{Kind: NotificationNewChapter, Manga: &Manga{ID: "m2"}, Chapter: chapter})
+		if err != nil || watched {
+			t.Errorf("notifyWatchers() = %v, %v, want false, nil", watched, err)
+		}
+		if discord.recipients != nil || email.recipients != nil {
+			t.Error("no backend should be called for a series without watchers")
+		}
+	})
+}
@@ -0,0 +1,310 @@
+// outbound_webhook.go (Webhook keluar untuk event chapter baru: /webhook)
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxWebhooksPerOwner     = 5
+	webhookDeliveryAttempts = 3
+	webhookRetryBaseDelay   = 2 * time.Second
+	// Circuit terbuka setelah sekian pengiriman gagal berturut-turut, lalu dicoba lagi setelah cooldown
+	webhookCircuitThreshold = 5
+	webhookCircuitCooldown  = time.Hour
+)
+
+var (
+	// webhookHTTPClient menolak alamat internal agar URL webhook tidak bisa dipakai untuk SSRF
+	webhookHTTPClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: 5 * time.Second, Control: blockPrivateAddress}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	// webhookDeliveries ditunggu saat shutdown agar pengiriman yang sedang berjalan selesai
+	webhookDeliveries sync.WaitGroup
+
+	// knownGuildUsers menghindari tulis ulang guild_users di setiap interaksi
+	knownGuildUsers sync.Map
+)
+
+type chapterEventPayload struct {
+	Event     string    `json:"event"`
+	Manga     Manga     `json:"manga"`
+	Chapter   Chapter   `json:"chapter"`
+	ReaderURL string    `json:"reader_url"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// rememberGuildUser mencatat bahwa user adalah member server tempat interaksi terjadi, sehingga
+// webhook server hanya menerima event series yang diikuti member-nya.
+func rememberGuildUser(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+	if i.GuildID == "" || userID == "" {
+		return
+	}
+	key := i.GuildID + "/" + userID
+	if _, ok := knownGuildUsers.Load(key); ok {
+		return
+	}
+	if err := RecordGuildUser(db, i.GuildID, userID); err != nil {
+		logFor(i).Error("Failed to record guild user", "error", err)
+		return
+	}
+	knownGuildUsers.Store(key, struct{}{})
+}
+
+func blockPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("URL tidak valid")
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("URL webhook harus memakai https")
+	}
+	return nil
+}
+
+// generateSecret menghasilkan token acak 256-bit (hex) untuk secret webhook dan URL feed.
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// dispatchChapterEvent mengirim event chapter baru ke semua webhook yang berlangganan, di background.
+func dispatchChapterEvent(manga *Manga, chapter *Chapter) {
+	hooks, err := GetOutboundWebhooksForManga(db, manga.ID)
+	if err != nil {
+		slog.Error("Failed to get outbound webhooks", "manga_id", manga.ID, "error", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	body, err := json.Marshal(chapterEventPayload{
+		Event:     "chapter.new",
+		Manga:     *manga,
+		Chapter:   *chapter,
+		ReaderURL: fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, chapter.ID),
+		SentAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		slog.Error("Failed to encode chapter event", "manga_id", manga.ID, "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, hook := range hooks {
+		if hook.DisabledUntil.After(now) {
+			webhookDeliveriesTotal.WithLabelValues("circuit_open").Inc()
+			continue
+		}
+		webhookDeliveries.Add(1)
+		go func(hook OutboundWebhook) {
+			defer webhookDeliveries.Done()
+			deliverWebhook(hook, body)
+		}(hook)
+	}
+}
+
+func deliverWebhook(hook OutboundWebhook, body []byte) {
+	log := slog.With("webhook_id", hook.ID, "owner_type", hook.OwnerType, "owner_id", hook.OwnerID)
+	var lastErr error
+	for attempt := 1; attempt <= webhookDeliveryAttempts; attempt++ {
+		if attempt > 1 {
+			// Backoff 2s, 8s, ... dan berhenti mencoba saat shutdown
+			delay := webhookRetryBaseDelay << (2 * (attempt - 2))
+			select {
+			case <-appCtx.Done():
+				log.Warn("Webhook retry cancelled by shutdown", "error", lastErr)
+				return
+			case <-time.After(delay):
+			}
+		}
+		var retry bool
+		retry, lastErr = postWebhook(hook, body)
+		if lastErr == nil {
+			webhookDeliveriesTotal.WithLabelValues("delivered").Inc()
+			if hook.ConsecutiveFailures > 0 {
+				if err := RecordWebhookSuccess(db, hook.ID); err != nil {
+					log.Error("Failed to reset webhook failures", "error", err)
+				}
+			}
+			return
+		}
+		log.Warn("Webhook delivery attempt failed", "attempt", attempt, "error", lastErr)
+		if !retry {
+			break
+		}
+	}
+
+	webhookDeliveriesTotal.WithLabelValues("failed").Inc()
+	failures, err := RecordWebhookFailure(db, hook.ID, truncateTitle(lastErr.Error(), 200))
+	if err != nil {
+		log.Error("Failed to record webhook failure", "error", err)
+		return
+	}
+	if failures >= webhookCircuitThreshold {
+		until := time.Now().Add(webhookCircuitCooldown)
+		if err := SetWebhookDisabledUntil(db, hook.ID, until); err != nil {
+			log.Error("Failed to open webhook circuit", "error", err)
+			return
+		}
+		log.Warn("Webhook circuit opened", "failures", failures, "until", until)
+	}
+}
+
+// postWebhook mengembalikan retry=true untuk error yang mungkin sementara (jaringan, 5xx, 408, 429).
+func postWebhook(hook OutboundWebhook, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EveezeComicBot-Webhook/1.0")
+	req.Header.Set("X-Eveeze-Event", "chapter.new")
+	req.Header.Set(webhookSignatureHeader, signWebhookBody(hook.Secret, body))
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook returned status code: %d", resp.StatusCode)
+}
+
+func webhookCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	sub := i.ApplicationCommandData().Options[0]
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		options[opt.Name] = opt
+	}
+	respond := func(msg string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+		})
+	}
+
+	// Webhook server hanya boleh diatur oleh member dengan izin Manage Server
+	ownerType, ownerID := webhookOwnerUser, interactionUserID(i)
+	if opt, ok := options["cakupan"]; ok && opt.StringValue() == webhookOwnerGuild {
+		if i.Member.Permissions&discordgo.PermissionManageGuild == 0 {
+			respond("❌ Webhook server hanya bisa diatur oleh member dengan izin **Manage Server**.")
+			return
+		}
+		ownerType, ownerID = webhookOwnerGuild, i.GuildID
+	}
+
+	switch sub.Name {
+	case "tambah":
+		respond(addWebhook(i, ownerType, ownerID, options["url"].StringValue()))
+	case "lihat":
+		respond(listWebhooks(i, ownerType, ownerID))
+	case "hapus":
+		deleted, err := DeleteOutboundWebhook(db, options["id"].IntValue(), ownerType, ownerID)
+		switch {
+		case err != nil:
+			logFor(i).Error("Failed to delete outbound webhook", "error", err)
+			respond("Gagal menghapus webhook.")
+		case !deleted:
+			respond("❌ Webhook dengan ID tersebut tidak ditemukan.")
+		default:
+			respond("🗑️ Webhook telah dihapus.")
+		}
+	}
+}
+
+func addWebhook(i *discordgo.InteractionCreate, ownerType, ownerID, rawURL string) string {
+	if err := validateWebhookURL(rawURL); err != nil {
+		return "❌ " + err.Error() + "."
+	}
+	existing, err := GetOutboundWebhooks(db, ownerType, ownerID)
+	if err != nil {
+		logFor(i).Error("Failed to get outbound webhooks", "error", err)
+		return "Gagal membaca database."
+	}
+	if len(existing) >= maxWebhooksPerOwner {
+		return fmt.Sprintf("❌ Maksimal %d webhook. Hapus salah satu dengan `/webhook hapus`.", maxWebhooksPerOwner)
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		logFor(i).Error("Failed to generate webhook secret", "error", err)
+		return "Gagal membuat webhook."
+	}
+	id, err := AddOutboundWebhook(db, ownerType, ownerID, rawURL, secret, interactionUserID(i))
+	if err != nil {
+		logFor(i).Error("Failed to add outbound webhook", "error", err)
+		return "Gagal menyimpan webhook."
+	}
+	logFor(i).Info("Outbound webhook added", "webhook_id", id, "owner_type", ownerType)
+
+	scope := "series di watchlist Anda"
+	if ownerType == webhookOwnerGuild {
+		scope = "semua series yang dipantau bot"
+	}
+	return fmt.Sprintf("✅ Webhook `#%d` ditambahkan untuk %s.\n"+
+		"Setiap chapter baru akan dikirim sebagai `POST` JSON dengan header `%s: sha256=<HMAC-SHA256 body>`.\n"+
+		"Secret (hanya ditampilkan sekali): ||`%s`||", id, scope, webhookSignatureHeader, secret)
+}
+
+func listWebhooks(i *discordgo.InteractionCreate, ownerType, ownerID string) string {
+	hooks, err := GetOutboundWebhooks(db, ownerType, ownerID)
+	if err != nil {
+		logFor(i).Error("Failed to get outbound webhooks", "error", err)
+		return "Gagal membaca database."
+	}
+	if len(hooks) == 0 {
+		return "📭 Belum ada webhook. Tambahkan dengan `/webhook tambah`."
+	}
+	var lines []string
+	for _, hook := range hooks {
+		state := "✅ Aktif"
+		if hook.DisabledUntil.After(time.Now()) {
+			state = fmt.Sprintf("⛔ Dijeda sampai <t:%d:R>", hook.DisabledUntil.Unix())
+		}
+		line := fmt.Sprintf("`#%d` %s — %s", hook.ID, truncateTitle(hook.URL, 80), state)
+		if hook.ConsecutiveFailures > 0 {
+			line += fmt.Sprintf("\n└ gagal %dx berturut-turut: `%s`", hook.ConsecutiveFailures, truncateTitle(hook.LastError, 80))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
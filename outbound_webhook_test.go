@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestGetOutboundWebhooksForManga(t *testing.T) {
+	newTestDB(t)
+	for _, item := range []WatchlistItem{
+		{MangaID: "shared", UserID: "alice", MangaTitle: "Shared"},
+		{MangaID: "shared", UserID: "bob", MangaTitle: "Shared"},
+		{MangaID: "niche", UserID: "bob", MangaTitle: "Niche"},
+	} {
+		if err := AddToWatchlist(db, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// alice hanya ada di server A, bob hanya di server B
+	for _, gu := range [][2]string{{"guild-a", "alice"}, {"guild-b", "bob"}} {
+		if err := RecordGuildUser(db, gu[0], gu[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, hook := range []struct{ ownerType, ownerID string }{
+		{webhookOwnerGuild, "guild-a"},
+		{webhookOwnerGuild, "guild-b"},
+		{webhookOwnerGuild, "guild-empty"},
+		{webhookOwnerUser, "alice"},
+		{webhookOwnerUser, "bob"},
+	} {
+		if _, err := AddOutboundWebhook(db, hook.ownerType, hook.ownerID, "https://example.com/"+hook.ownerID, "secret", "admin"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		mangaID string
+		want    []string
+	}{
+		{"shared", []string{"alice", "bob", "guild-a", "guild-b"}},
+		{"niche", []string{"bob", "guild-b"}},
+		{"unwatched", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mangaID, func(t *testing.T) {
+			hooks, err := GetOutboundWebhooksForManga(db, tt.mangaID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var owners []string
+			for _, hook := range hooks {
+				owners = append(owners, hook.OwnerID)
+			}
+			sort.Strings(owners)
+			if len(owners) != len(tt.want) {
+				t.Fatalf("owners = %v, want %v", owners, tt.want)
+			}
+			for idx := range owners {
+				if owners[idx] != tt.want[idx] {
+					t.Fatalf("owners = %v, want %v", owners, tt.want)
+				}
+			}
+		})
+	}
+}
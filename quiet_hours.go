@@ -0,0 +1,59 @@
+// quiet_hours.go (Zona waktu per user dan jam tenang notifikasi)
+package main
+
+import (
+	"fmt"
+	"time"
+
+	// Basis data zona waktu ikut di-embed agar LoadLocation tetap jalan di image tanpa tzdata
+	_ "time/tzdata"
+)
+
+// defaultTimezone dipakai user yang belum memilih zona waktu
+const defaultTimezone = "Asia/Jakarta"
+
+var defaultLocation = time.FixedZone("WIB", 7*60*60)
+
+// TimezoneName mengembalikan zona waktu efektif user.
+func (p NotificationPrefs) TimezoneName() string {
+	if p.Timezone == "" {
+		return defaultTimezone
+	}
+	return p.Timezone
+}
+
+// Location mengembalikan zona waktu user; zona yang tidak dikenal jatuh ke default.
+func (p NotificationPrefs) Location() *time.Location {
+	if p.Timezone == "" {
+		return defaultLocation
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return defaultLocation
+	}
+	return loc
+}
+
+func (p NotificationPrefs) HasQuietHours() bool {
+	return p.QuietStart >= 0 && p.QuietEnd >= 0 && p.QuietStart != p.QuietEnd
+}
+
+// InQuietHours melaporkan apakah now jatuh di jam tenang user. Rentang boleh melewati
+// tengah malam, misalnya 22–7.
+func (p NotificationPrefs) InQuietHours(now time.Time) bool {
+	if !p.HasQuietHours() {
+		return false
+	}
+	hour := now.In(p.Location()).Hour()
+	if p.QuietStart < p.QuietEnd {
+		return hour >= p.QuietStart && hour < p.QuietEnd
+	}
+	return hour >= p.QuietStart || hour < p.QuietEnd
+}
+
+func describeQuietHours(prefs NotificationPrefs) string {
+	if !prefs.HasQuietHours() {
+		return "nonaktif"
+	}
+	return fmt.Sprintf("%02d:00–%02d:00", prefs.QuietStart, prefs.QuietEnd)
+}
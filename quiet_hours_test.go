@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInQuietHours(t *testing.T) {
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		timezone string
+		start    int
+		end      int
+		now      time.Time
+		want     bool
+	}{
+		{"disabled", "", -1, -1, utc(6, 1, 16, 0), false},
+		// Zona default WIB (UTC+7)
+		{"across midnight, before midnight", "", 22, 7, utc(6, 1, 16, 0), true},
+		{"across midnight, after midnight", "", 22, 7, utc(6, 1, 20, 0), true},
+		{"across midnight, start is inclusive", "", 22, 7, utc(6, 1, 15, 0), true},
+		{"across midnight, end is exclusive", "", 22, 7, utc(6, 1, 0, 0), false},
+		{"across midnight, daytime", "", 22, 7, utc(6, 1, 5, 0), false},
+		{"same day range, inside", "Asia/Jakarta", 13, 17, utc(6, 1, 7, 0), true},
+		{"same day range, end is exclusive", "Asia/Jakarta", 13, 17, utc(6, 1, 10, 0), false},
+		{"same day range, before start", "Asia/Jakarta", 13, 17, utc(6, 1, 5, 59), false},
+		// Jam lokal mengikuti DST: 21:30 UTC adalah 22:30 BST di musim panas, 21:30 GMT di musim dingin
+		{"london summer time", "Europe/London", 22, 7, utc(6, 1, 21, 30), true},
+		{"london winter time", "Europe/London", 22, 7, utc(1, 15, 21, 30), false},
+		{"new york evening", "America/New_York", 22, 7, utc(6, 2, 3, 0), true},
+		{"same instant in default zone", "", 22, 7, utc(6, 2, 3, 0), false},
+		{"unknown zone falls back to default", "Mars/Olympus_Mons", 22, 7, utc(6, 1, 16, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := NotificationPrefs{Timezone: tt.timezone, QuietStart: tt.start, QuietEnd: tt.end}
+			if got := prefs.InQuietHours(tt.now); got != tt.want {
+				t.Errorf("InQuietHours(%s) = %v, want %v", tt.now.In(prefs.Location()).Format("15:04 MST"), got, tt.want)
+			}
+		})
+	}
+}
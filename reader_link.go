@@ -0,0 +1,80 @@
+// reader_link.go (Parsing link READER_BASE_URL)
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type readerLink struct {
+	MangaID   string
+	ChapterID string
+}
+
+// parseReaderLink menerima link manga/chapter di bawah READER_BASE_URL atau ID manga polos.
+func parseReaderLink(input string) (*readerLink, error) {
+	input = strings.TrimSpace(strings.Trim(input, "<>"))
+	if input == "" {
+		return nil, fmt.Errorf("empty reader link")
+	}
+	if !strings.Contains(input, "://") {
+		if strings.ContainsAny(input, "/? ") {
+			return nil, fmt.Errorf("invalid manga id: %s", input)
+		}
+		return &readerLink{MangaID: input}, nil
+	}
+
+	linkURL, err := url.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := url.Parse(cfg.ReaderBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(linkURL.Host, baseURL.Host) {
+		return nil, fmt.Errorf("link is not under reader base url: %s", input)
+	}
+
+	basePath := strings.TrimSuffix(baseURL.Path, "/")
+	if !strings.HasPrefix(linkURL.Path, basePath+"/") {
+		return nil, fmt.Errorf("link is not under reader base url: %s", input)
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(linkURL.Path, basePath), "/"), "/")
+	if len(parts) < 2 || parts[1] == "" {
+		return nil, fmt.Errorf("unrecognized reader link: %s", input)
+	}
+	switch parts[0] {
+	case "chapter":
+		return &readerLink{ChapterID: parts[1]}, nil
+	case "manga", "comic", "series":
+		return &readerLink{MangaID: parts[1]}, nil
+	}
+	return nil, fmt.Errorf("unrecognized reader link: %s", input)
+}
+
+// resolveReaderLink mengambil detail manga (dan chapter, jika link chapter) dari API.
+func resolveReaderLink(link *readerLink) (*Manga, *Chapter, error) {
+	var chapter *Chapter
+	mangaID := link.MangaID
+	if link.ChapterID != "" {
+		var err error
+		chapter, err = GetChapterDetails(link.ChapterID)
+		if err != nil {
+			return nil, nil, err
+		}
+		mangaID = chapter.MangaID
+	}
+	if mangaID == "" {
+		return nil, nil, fmt.Errorf("could not resolve manga for chapter %s", link.ChapterID)
+	}
+	manga, err := GetMangaDetails(mangaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manga.ID == "" {
+		return nil, nil, fmt.Errorf("manga %s not found", mangaID)
+	}
+	return manga, chapter, nil
+}
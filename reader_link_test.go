@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseReaderLink(t *testing.T) {
+	previous := cfg
+	cfg = &Config{ReaderBaseURL: "https://reader.example/app/"}
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		name    string
+		input   string
+		want    readerLink
+		wantErr bool
+	}{
+		{name: "plain manga id", input: " one-piece ", want: readerLink{MangaID: "one-piece"}},
+		{name: "manga link", input: "https://reader.example/app/manga/one-piece", want: readerLink{MangaID: "one-piece"}},
+		{name: "series alias with trailing slash", input: "https://reader.example/app/series/one-piece/", want: readerLink{MangaID: "one-piece"}},
+		{name: "chapter link with query", input: "https://READER.example/app/chapter/c-1100?page=3", want: readerLink{ChapterID: "c-1100"}},
+		{name: "discord suppressed embed", input: "<https://reader.example/app/comic/one-piece>", want: readerLink{MangaID: "one-piece"}},
+		{name: "other host", input: "https://evil.example/app/manga/one-piece", wantErr: true},
+		{name: "outside base path", input: "https://reader.example/other/manga/one-piece", wantErr: true},
+		{name: "unknown section", input: "https://reader.example/app/user/alice", wantErr: true},
+		{name: "missing id", input: "https://reader.example/app/manga/", wantErr: true},
+		{name: "id with slash", input: "manga/one-piece", wantErr: true},
+		{name: "empty", input: "  ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReaderLink(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseReaderLink(%q) = %+v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReaderLink(%q) error = %v", tt.input, err)
+			}
+			if *got != tt.want {
+				t.Errorf("parseReaderLink(%q) = %+v, want %+v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// reader_sync.go (Sinkronisasi progres dari situs reader: /link dan callback /callbacks/reader/...)
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	linkCodeLength = 8
+	linkCodeTTL    = 10 * time.Minute
+	// Tanpa huruf/angka yang mirip (0/O, 1/I/L) agar mudah diketik ulang
+	linkCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+func generateLinkCode() (string, error) {
+	var code strings.Builder
+	alphabetSize := big.NewInt(int64(len(linkCodeAlphabet)))
+	for range linkCodeLength {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(linkCodeAlphabet[n.Int64()])
+	}
+	return code.String(), nil
+}
+
+func linkCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	respond := func(msg string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+		})
+	}
+	userID := interactionUserID(i)
+
+	if i.ApplicationCommandData().Options[0].Name == "putus" {
+		unlinked, err := UnlinkReaderAccount(db, userID)
+		switch {
+		case err != nil:
+			logFor(i).Error("Failed to unlink reader account", "error", err)
+			respond("Gagal memutus tautan akun.")
+		case !unlinked:
+			respond("ℹ️ Akun Discord Anda belum tertaut ke akun reader.")
+		default:
+			logFor(i).Info("Reader account unlinked")
+			respond("🔌 Tautan ke akun reader telah diputus. Progres tidak lagi disinkronkan.")
+		}
+		return
+	}
+
+	if cfg.ReaderCallbackSecret == "" {
+		respond("❌ Sinkronisasi progres belum diaktifkan oleh operator bot.")
+		return
+	}
+	code, err := generateLinkCode()
+	if err != nil {
+		logFor(i).Error("Failed to generate link code", "error", err)
+		respond("Gagal membuat kode.")
+		return
+	}
+	expiresAt := time.Now().Add(linkCodeTTL)
+	if err := SetLinkCode(db, userID, code, expiresAt); err != nil {
+		logFor(i).Error("Failed to save link code", "error", err)
+		respond("Gagal membuat kode.")
+		return
+	}
+	respond(fmt.Sprintf("🔗 Masukkan kode ini di halaman akun %s:\n# `%s`\nKode hanya bisa dipakai sekali dan kedaluwarsa <t:%d:R>.\n"+
+		"Setelah tertaut, chapter yang selesai Anda baca di reader akan otomatis tercatat di watchlist.",
+		cfg.ReaderBaseURL, code, expiresAt.Unix()))
+}
+
+func writeReaderCallbackResult(w http.ResponseWriter, endpoint string, status int, result string) {
+	readerCallbacksTotal.WithLabelValues(endpoint, result).Inc()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"result": result})
+}
+
+// readSignedReaderCallback membaca body POST dan memverifikasi signature-nya dengan READER_CALLBACK_SECRET.
+func readSignedReaderCallback(w http.ResponseWriter, r *http.Request, endpoint string, v any) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		writeReaderCallbackResult(w, endpoint, http.StatusRequestEntityTooLarge, "invalid_body")
+		return false
+	}
+	if !verifyWebhookSignature(cfg.ReaderCallbackSecret, body, r.Header.Get(webhookSignatureHeader)) {
+		slog.Warn("Rejected reader callback with invalid signature", "endpoint", endpoint, "remote_addr", r.RemoteAddr)
+		writeReaderCallbackResult(w, endpoint, http.StatusUnauthorized, "invalid_signature")
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		writeReaderCallbackResult(w, endpoint, http.StatusBadRequest, "invalid_body")
+		return false
+	}
+	return true
+}
+
+// readerLinkCallbackHandler dipanggil situs reader saat user memasukkan kode dari /link.
+func readerLinkCallbackHandler(s *discordgo.Session) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Code         string `json:"code"`
+			ReaderUserID string `json:"reader_user_id"`
+		}
+		if !readSignedReaderCallback(w, r, "link", &event) {
+			return
+		}
+		if event.Code == "" || event.ReaderUserID == "" {
+			writeReaderCallbackResult(w, "link", http.StatusBadRequest, "invalid_body")
+			return
+		}
+		userID, err := RedeemLinkCode(db, strings.ToUpper(strings.TrimSpace(event.Code)), event.ReaderUserID, time.Now())
+		if err == sql.ErrNoRows {
+			writeReaderCallbackResult(w, "link", http.StatusNotFound, "invalid_code")
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to redeem link code", "reader_user_id", event.ReaderUserID, "error", err)
+			writeReaderCallbackResult(w, "link", http.StatusInternalServerError, "error")
+			return
+		}
+		slog.Info("Reader account linked", "user_id", userID, "reader_user_id", event.ReaderUserID)
+		writeReaderCallbackResult(w, "link", http.StatusOK, "linked")
+
+		// Konfirmasi lewat DM; gagal kirim (DM ditutup) tidak membatalkan penautan
+		if channel, err := s.UserChannelCreate(userID); err == nil {
+			s.ChannelMessageSend(channel.ID, "✅ Akun reader Anda sudah tertaut. Progres baca kini disinkronkan otomatis ke watchlist.")
+		}
+	}
+}
+
+// readerProgressCallbackHandler dipanggil situs reader saat user yang tertaut selesai membaca chapter.
+// Progres hanya dimajukan, tidak pernah dimundurkan.
+func readerProgressCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	var event struct {
+		ReaderUserID  string  `json:"reader_user_id"`
+		MangaID       string  `json:"manga_id"`
+		ChapterID     string  `json:"chapter_id"`
+		ChapterNumber float64 `json:"chapter_number"`
+	}
+	if !readSignedReaderCallback(w, r, "progress", &event) {
+		return
+	}
+	if event.ReaderUserID == "" || event.ChapterID == "" {
+		writeReaderCallbackResult(w, "progress", http.StatusBadRequest, "invalid_body")
+		return
+	}
+	userID, err := GetUserByReaderAccount(db, event.ReaderUserID)
+	if err == sql.ErrNoRows {
+		writeReaderCallbackResult(w, "progress", http.StatusNotFound, "not_linked")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to get linked reader account", "reader_user_id", event.ReaderUserID, "error", err)
+		writeReaderCallbackResult(w, "progress", http.StatusInternalServerError, "error")
+		return
+	}
+
+	// Lengkapi manga/nomor chapter dari API jika tidak dikirim oleh reader
+	if event.MangaID == "" || event.ChapterNumber == 0 {
+		chapter, err := GetChapterDetails(event.ChapterID)
+		if err != nil {
+			writeReaderCallbackResult(w, "progress", http.StatusBadGateway, "chapter_lookup_failed")
+			return
+		}
+		event.ChapterNumber = chapter.Number
+		if event.MangaID == "" {
+			event.MangaID = chapter.MangaID
+		}
+	}
+
+	item, err := GetWatchlistItem(db, userID, event.MangaID)
+	if err != nil {
+		writeReaderCallbackResult(w, "progress", http.StatusOK, "not_watched")
+		return
+	}
+	if event.ChapterNumber <= item.UserProgressChapterNumber {
+		writeReaderCallbackResult(w, "progress", http.StatusOK, "not_newer")
+		return
+	}
+	if err := UpdateUserProgress(db, userID, event.MangaID, event.ChapterID, event.ChapterNumber); err != nil {
+		slog.Error("Failed to sync reader progress", "user_id", userID, "manga_id", event.MangaID, "error", err)
+		writeReaderCallbackResult(w, "progress", http.StatusInternalServerError, "error")
+		return
+	}
+	slog.Debug("Reader progress synced", "user_id", userID, "manga_id", event.MangaID, "chapter_id", event.ChapterID)
+	writeReaderCallbackResult(w, "progress", http.StatusOK, "updated")
+}
+
+func purgeExpiredLinkCodes() {
+	if err := PurgeExpiredLinkCodes(db, time.Now()); err != nil {
+		slog.Error("Failed to purge expired link codes", "error", err)
+	}
+}
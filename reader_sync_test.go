@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReaderProgressCallback(t *testing.T) {
+	newTestDB(t)
+	previous := cfg
+	cfg = &Config{ReaderCallbackSecret: "reader-secret"}
+	t.Cleanup(func() { cfg = previous })
+
+	if err := SetLinkCode(db, "u1", "ABC123", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RedeemLinkCode(db, "ABC123", "r1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToWatchlist(db, WatchlistItem{MangaID: "m1", UserID: "u1", MangaTitle: "Manga", UserProgressChapterID: "c10", UserProgressChapterNumber: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body, secret string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/reader/progress", strings.NewReader(body))
+		req.Header.Set(webhookSignatureHeader, signWebhookBody(secret, []byte(body)))
+		rec := httptest.NewRecorder()
+		readerProgressCallbackHandler(rec, req)
+		var resp struct {
+			Result string `json:"result"`
+		}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp.Result
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		secret     string
+		wantStatus int
+		wantResult string
+	}{
+		{"invalid signature", `{"reader_user_id":"r1","manga_id":"m1","chapter_id":"c11","chapter_number":11}`, "wrong", http.StatusUnauthorized, "invalid_signature"},
+		{"unlinked reader account", `{"reader_user_id":"r2","manga_id":"m1","chapter_id":"c11","chapter_number":11}`, "reader-secret", http.StatusNotFound, "not_linked"},
+		{"series not watched", `{"reader_user_id":"r1","manga_id":"m2","chapter_id":"c11","chapter_number":11}`, "reader-secret", http.StatusOK, "not_watched"},
+		{"older chapter", `{"reader_user_id":"r1","manga_id":"m1","chapter_id":"c9","chapter_number":9}`, "reader-secret", http.StatusOK, "not_newer"},
+		{"newer chapter", `{"reader_user_id":"r1","manga_id":"m1","chapter_id":"c11","chapter_number":11}`, "reader-secret", http.StatusOK, "updated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := post(tt.body, tt.secret)
+			if status != tt.wantStatus || result != tt.wantResult {
+				t.Errorf("got %d %q, want %d %q", status, result, tt.wantStatus, tt.wantResult)
+			}
+		})
+	}
+
+	item, err := GetWatchlistItem(db, "u1", "m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.UserProgressChapterID != "c11" || item.UserProgressChapterNumber != 11 {
+		t.Errorf("progress = %s (%.1f), want c11 (11.0)", item.UserProgressChapterID, item.UserProgressChapterNumber)
+	}
+}
@@ -0,0 +1,344 @@
+// rest_api.go (REST API JSON untuk watchlist dan progres: /api/v1, token lewat /token)
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	apiTokenPrefix = "evz_"
+	// Token bucket per user: rata-rata apiRateLimit request per menit dengan burst apiRateBurst
+	apiRateLimit       = 60
+	apiRateBurst       = 30
+	apiMaxBodySize     = 16 << 10
+	apiHistoryDefault  = 50
+	apiHistoryMaxLimit = 200
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+var apiLimiter = &rateLimiter{buckets: make(map[string]*tokenBucket)}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// allow mengembalikan false dan waktu tunggu jika user sudah melewati batas.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	perSecond := float64(apiRateLimit) / 60
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: apiRateBurst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(apiRateBurst, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+type apiProgress struct {
+	ChapterID     string  `json:"chapter_id"`
+	ChapterNumber float64 `json:"chapter_number"`
+}
+
+type apiWatchlistEntry struct {
+	MangaID  string      `json:"manga_id"`
+	Title    string      `json:"title"`
+	Shelf    string      `json:"shelf"`
+	Progress apiProgress `json:"progress"`
+}
+
+type apiHistoryEntry struct {
+	MangaID       string    `json:"manga_id"`
+	Title         string    `json:"title"`
+	ChapterID     string    `json:"chapter_id"`
+	ChapterNumber float64   `json:"chapter_number"`
+	ReleaseDate   string    `json:"release_date"`
+	SeenAt        time.Time `json:"seen_at"`
+	ReaderURL     string    `json:"reader_url"`
+}
+
+func toAPIWatchlistEntry(item WatchlistItem) apiWatchlistEntry {
+	return apiWatchlistEntry{
+		MangaID: item.MangaID, Title: item.MangaTitle, Shelf: item.Shelf,
+		Progress: apiProgress{ChapterID: item.UserProgressChapterID, ChapterNumber: item.UserProgressChapterNumber},
+	}
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	writeAPIJSON(w, status, map[string]string{"error": msg})
+}
+
+// statusRecorder menyimpan status response untuk label metrik
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// apiRoute membungkus handler dengan autentikasi Bearer token, rate limit dan metrik.
+func apiRoute(handler func(w http.ResponseWriter, r *http.Request, userID string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() { restAPIRequestsTotal.WithLabelValues(r.Pattern, strconv.Itoa(rec.status)).Inc() }()
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(token, apiTokenPrefix) {
+			writeAPIError(rec, http.StatusUnauthorized, "missing or malformed bearer token")
+			return
+		}
+		userID, err := GetUserByAPIToken(db, hashAPIToken(token))
+		if err == sql.ErrNoRows {
+			writeAPIError(rec, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to look up API token", "error", err)
+			writeAPIError(rec, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if allowed, wait := apiLimiter.allow(userID, time.Now()); !allowed {
+			rec.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeAPIError(rec, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		handler(rec, r, userID)
+	}
+}
+
+// newRESTAPIHandler mendaftarkan semua route /api/v1 pada mux tersendiri.
+func newRESTAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	})
+	mux.HandleFunc("GET /api/v1/watchlist", apiRoute(apiListWatchlist))
+	mux.HandleFunc("POST /api/v1/watchlist", apiRoute(apiAddWatchlist))
+	mux.HandleFunc("DELETE /api/v1/watchlist/{manga_id}", apiRoute(apiRemoveWatchlist))
+	mux.HandleFunc("GET /api/v1/watchlist/{manga_id}/progress", apiRoute(apiGetProgress))
+	mux.HandleFunc("PUT /api/v1/watchlist/{manga_id}/progress", apiRoute(apiSetProgress))
+	mux.HandleFunc("GET /api/v1/history", apiRoute(apiHistory))
+	return mux
+}
+
+func apiListWatchlist(w http.ResponseWriter, r *http.Request, userID string) {
+	items, err := GetWatchlistForUser(db, userID)
+	if err != nil {
+		slog.Error("Failed to get watchlist", "user_id", userID, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	entries := []apiWatchlistEntry{}
+	for _, item := range items {
+		entries = append(entries, toAPIWatchlistEntry(item))
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{"data": entries})
+}
+
+func apiAddWatchlist(w http.ResponseWriter, r *http.Request, userID string) {
+	var body struct {
+		MangaID string `json:"manga_id"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize)).Decode(&body); err != nil || body.MangaID == "" {
+		writeAPIError(w, http.StatusBadRequest, "body must be JSON with a manga_id")
+		return
+	}
+	if _, err := GetWatchlistItem(db, userID, body.MangaID); err == nil {
+		writeAPIError(w, http.StatusConflict, "manga is already on the watchlist")
+		return
+	}
+	manga, err := GetMangaDetails(body.MangaID)
+	if isNotFound(err) || (err == nil && manga.ID == "") {
+		writeAPIError(w, http.StatusNotFound, "manga not found")
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "source API unavailable")
+		return
+	}
+	if err := watchManga(userID, manga); err != nil {
+		slog.Error("Failed to add to watchlist", "user_id", userID, "manga_id", manga.ID, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	item, err := GetWatchlistItem(db, userID, manga.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeAPIJSON(w, http.StatusCreated, toAPIWatchlistEntry(*item))
+}
+
+func apiRemoveWatchlist(w http.ResponseWriter, r *http.Request, userID string) {
+	mangaID := r.PathValue("manga_id")
+	if _, err := GetWatchlistItem(db, userID, mangaID); err != nil {
+		writeAPIError(w, http.StatusNotFound, "manga is not on the watchlist")
+		return
+	}
+	// Soft-delete seperti penghapusan lewat Discord; baris di-purge setelah undoWindow
+	if err := SoftDeleteFromWatchlist(db, mangaID, userID, time.Now().UnixMilli()); err != nil {
+		slog.Error("Failed to delete from watchlist", "user_id", userID, "manga_id", mangaID, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiGetProgress(w http.ResponseWriter, r *http.Request, userID string) {
+	item, err := GetWatchlistItem(db, userID, r.PathValue("manga_id"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "manga is not on the watchlist")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, toAPIWatchlistEntry(*item).Progress)
+}
+
+func apiSetProgress(w http.ResponseWriter, r *http.Request, userID string) {
+	mangaID := r.PathValue("manga_id")
+	if _, err := GetWatchlistItem(db, userID, mangaID); err != nil {
+		writeAPIError(w, http.StatusNotFound, "manga is not on the watchlist")
+		return
+	}
+	var body struct {
+		ChapterID string `json:"chapter_id"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize)).Decode(&body); err != nil || body.ChapterID == "" {
+		writeAPIError(w, http.StatusBadRequest, "body must be JSON with a chapter_id")
+		return
+	}
+	chapter, err := GetChapterDetails(body.ChapterID)
+	if isNotFound(err) {
+		writeAPIError(w, http.StatusNotFound, "chapter not found")
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "source API unavailable")
+		return
+	}
+	if chapter.MangaID != "" && chapter.MangaID != mangaID {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("chapter %s does not belong to manga %s", chapter.ID, mangaID))
+		return
+	}
+	if err := UpdateUserProgress(db, userID, mangaID, chapter.ID, chapter.Number); err != nil {
+		slog.Error("Failed to update progress", "user_id", userID, "manga_id", mangaID, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, apiProgress{ChapterID: chapter.ID, ChapterNumber: chapter.Number})
+}
+
+func apiHistory(w http.ResponseWriter, r *http.Request, userID string) {
+	limit := apiHistoryDefault
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > apiHistoryMaxLimit {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", apiHistoryMaxLimit))
+			return
+		}
+		limit = n
+	}
+	entries, err := GetFeedEntriesForUser(db, userID, limit)
+	if err != nil {
+		slog.Error("Failed to get history", "user_id", userID, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	history := []apiHistoryEntry{}
+	for _, entry := range entries {
+		history = append(history, apiHistoryEntry{
+			MangaID: entry.MangaID, Title: entry.MangaTitle, ChapterID: entry.ChapterID,
+			ChapterNumber: entry.ChapterNumber, ReleaseDate: entry.ReleaseDate, SeenAt: entry.SeenAt,
+			ReaderURL: fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, entry.ChapterID),
+		})
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{"data": history})
+}
+
+func tokenCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	respond := func(msg string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+		})
+	}
+	userID := interactionUserID(i)
+
+	if i.ApplicationCommandData().Options[0].Name == "cabut" {
+		revoked, err := RevokeAPIToken(db, userID)
+		switch {
+		case err != nil:
+			logFor(i).Error("Failed to revoke API token", "error", err)
+			respond("Gagal mencabut token.")
+		case !revoked:
+			respond("ℹ️ Anda belum punya token API.")
+		default:
+			logFor(i).Info("API token revoked")
+			respond("🗑️ Token API Anda telah dicabut.")
+		}
+		return
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		logFor(i).Error("Failed to generate API token", "error", err)
+		respond("Gagal membuat token.")
+		return
+	}
+	token := apiTokenPrefix + secret
+	if err := SetAPIToken(db, userID, hashAPIToken(token)); err != nil {
+		logFor(i).Error("Failed to save API token", "error", err)
+		respond("Gagal membuat token.")
+		return
+	}
+	logFor(i).Info("API token issued")
+
+	msg := "🔑 Token API baru Anda (hanya ditampilkan sekali, token lama otomatis tidak berlaku):\n||`" + token + "`||\n\n" +
+		"Kirim sebagai header `Authorization: Bearer <token>`."
+	if cfg.PublicBaseURL != "" {
+		msg += fmt.Sprintf("\nDokumentasi: %s/api/v1/openapi.json", cfg.PublicBaseURL)
+	}
+	respond(msg)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	perToken := time.Minute / apiRateLimit
+	tests := []struct {
+		name     string
+		requests int           // request berturut-turut pada waktu start
+		after    time.Duration // jeda sebelum request terakhir
+		want     bool
+		wantWait time.Duration
+	}{
+		{"first request", 0, 0, true, 0},
+		{"within burst", apiRateBurst - 1, 0, true, 0},
+		{"burst exhausted", apiRateBurst, 0, false, perToken},
+		{"half a token refilled", apiRateBurst, perToken / 2, false, perToken / 2},
+		{"one token refilled", apiRateBurst, perToken, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &rateLimiter{buckets: make(map[string]*tokenBucket)}
+			for n := range tt.requests {
+				if ok, _ := limiter.allow("user", start); !ok {
+					t.Fatalf("request %d rejected within burst", n+1)
+				}
+			}
+			ok, wait := limiter.allow("user", start.Add(tt.after))
+			if ok != tt.want || (wait-tt.wantWait).Abs() > time.Millisecond {
+				t.Errorf("allow() = %v, %s; want %v, %s", ok, wait, tt.want, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestRateLimiterKeysAreIndependent(t *testing.T) {
+	limiter := &rateLimiter{buckets: make(map[string]*tokenBucket)}
+	now := time.Now()
+	for range apiRateBurst {
+		limiter.allow("busy", now)
+	}
+	if ok, _ := limiter.allow("busy", now); ok {
+		t.Fatal("busy user should be rate limited")
+	}
+	if ok, _ := limiter.allow("other", now); !ok {
+		t.Error("other user should not share the busy user's bucket")
+	}
+}
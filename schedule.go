@@ -0,0 +1,100 @@
+// schedule.go (Jadwal polling adaptif per series)
+package main
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// schedulerTick adalah seberapa sering checker mencari series yang sudah jatuh tempo
+	schedulerTick = time.Minute
+	// defaultPollInterval dipakai selama ritme rilis series belum diketahui
+	defaultPollInterval = 30 * time.Minute
+	// cadenceSampleSize adalah jumlah chapter terbaru yang dipakai untuk menghitung ritme rilis
+	cadenceSampleSize = 10
+	// checksPerRelease: series dicek beberapa kali dalam satu jarak rilis
+	checksPerRelease = 4
+)
+
+// releaseCadence menghitung median jarak antar Chapter.ReleaseDate dan waktu rilis terakhir.
+// interval bernilai 0 jika tanggal rilis yang valid kurang dari dua.
+func releaseCadence(chapters []Chapter) (interval time.Duration, lastRelease time.Time) {
+	var releases []time.Time
+	for _, chapter := range chapters {
+		t, err := time.Parse(time.RFC3339, chapter.ReleaseDate)
+		if err != nil {
+			continue
+		}
+		releases = append(releases, t)
+	}
+	if len(releases) == 0 {
+		return 0, time.Time{}
+	}
+	sort.Slice(releases, func(a, b int) bool { return releases[a].After(releases[b]) })
+	lastRelease = releases[0]
+
+	var gaps []time.Duration
+	for idx := 1; idx < len(releases); idx++ {
+		// Chapter yang rilis bersamaan (batch upload) tidak dihitung sebagai jarak
+		if gap := releases[idx-1].Sub(releases[idx]); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	if len(gaps) == 0 {
+		return 0, lastRelease
+	}
+	sort.Slice(gaps, func(a, b int) bool { return gaps[a] < gaps[b] })
+	return gaps[len(gaps)/2], lastRelease
+}
+
+// nextCheckInterval: series yang rilis lebih sering dan punya lebih banyak watcher dicek lebih sering,
+// series yang lama tidak rilis (hiatus) makin jarang dicek.
+func nextCheckInterval(schedule MangaSchedule, now time.Time) time.Duration {
+	if schedule.Unavailable {
+		return cfg.PollMaxInterval
+	}
+	interval := defaultPollInterval
+	if schedule.ReleaseInterval > 0 {
+		gap := schedule.ReleaseInterval
+		if since := now.Sub(schedule.LastReleaseAt); since > gap {
+			gap = since
+		}
+		interval = gap / checksPerRelease
+	}
+	if schedule.Watchers > 1 {
+		interval = time.Duration(float64(interval) / (1 + math.Log2(float64(schedule.Watchers))))
+	}
+	return min(max(interval, cfg.PollMinInterval), cfg.PollMaxInterval)
+}
+
+// scheduleNextCheck menyimpan waktu cek berikutnya. refreshCadence memaksa ritme rilis
+// dihitung ulang dari API (misalnya setelah chapter terbaru berubah).
+func scheduleNextCheck(mangaID string, refreshCadence bool) {
+	schedule, err := GetMangaSchedule(db, mangaID)
+	if err != nil {
+		slog.Error("Failed to get manga schedule", "manga_id", mangaID, "error", err)
+		return
+	}
+	if (refreshCadence || schedule.LastReleaseAt.IsZero()) && !schedule.Unavailable {
+		if chapters, err := GetChapterList(mangaID, 1, cadenceSampleSize); err != nil {
+			slog.Warn("Failed to get chapters for release cadence", "manga_id", mangaID, "error", err)
+		} else {
+			schedule.ReleaseInterval, schedule.LastReleaseAt = releaseCadence(chapters.Data)
+			if err := SetMangaCadence(db, mangaID, schedule.ReleaseInterval, schedule.LastReleaseAt); err != nil {
+				slog.Error("Failed to save release cadence", "manga_id", mangaID, "error", err)
+			}
+		}
+	}
+
+	now := time.Now()
+	interval := nextCheckInterval(schedule, now)
+	if err := SetNextCheck(db, mangaID, now.Add(interval)); err != nil {
+		slog.Error("Failed to save next check time", "manga_id", mangaID, "error", err)
+		return
+	}
+	slog.Debug("Next check scheduled", "manga_id", mangaID, "interval", interval,
+		"release_interval", schedule.ReleaseInterval, "watchers", schedule.Watchers)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextCheckInterval(t *testing.T) {
+	previous := cfg
+	cfg = &Config{PollMinInterval: 10 * time.Minute, PollMaxInterval: 24 * time.Hour}
+	t.Cleanup(func() { cfg = previous })
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		name     string
+		schedule MangaSchedule
+		want     time.Duration
+	}{
+		{"unavailable", MangaSchedule{Unavailable: true, ReleaseInterval: day, LastReleaseAt: now}, 24 * time.Hour},
+		{"unknown cadence", MangaSchedule{Watchers: 1}, defaultPollInterval},
+		{"unknown cadence, popular", MangaSchedule{Watchers: 4}, 10 * time.Minute},
+		{"clamped to minimum", MangaSchedule{Watchers: 16}, 10 * time.Minute},
+		{"daily release", MangaSchedule{ReleaseInterval: day, LastReleaseAt: now.Add(-time.Hour), Watchers: 1}, 6 * time.Hour},
+		{"daily release, two watchers", MangaSchedule{ReleaseInterval: day, LastReleaseAt: now.Add(-time.Hour), Watchers: 2}, 3 * time.Hour},
+		{"overdue release backs off", MangaSchedule{ReleaseInterval: day, LastReleaseAt: now.Add(-3 * day), Watchers: 1}, 18 * time.Hour},
+		{"hiatus clamped to maximum", MangaSchedule{ReleaseInterval: day, LastReleaseAt: now.Add(-10 * day), Watchers: 1}, 24 * time.Hour},
+		{"weekly release clamped to maximum", MangaSchedule{ReleaseInterval: 7 * day, LastReleaseAt: now.Add(-day), Watchers: 1}, 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCheckInterval(tt.schedule, now); got != tt.want {
+				t.Errorf("nextCheckInterval() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// series_roles.go (Role per series populer yang dikelola bot, dipakai sebagai pengganti mention per user)
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func isDiscordErrorCode(err error, code int) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == code
+}
+
+var (
+	// seriesRoleSyncRequests berisi series yang anggota role-nya perlu disinkronkan oleh runSeriesRoleSync
+	seriesRoleSyncRequests = make(chan Manga, 100)
+	seriesRoleSyncPending  sync.Map
+)
+
+// requestSeriesRoleSync menjadwalkan sinkronisasi role tanpa menunggu. Permintaan untuk series
+// yang sudah mengantre diabaikan; jika antrean penuh, sinkronisasi dicoba lagi di notifikasi berikutnya.
+func requestSeriesRoleSync(manga *Manga) {
+	if _, queued := seriesRoleSyncPending.LoadOrStore(manga.ID, struct{}{}); queued {
+		return
+	}
+	select {
+	case seriesRoleSyncRequests <- *manga:
+	default:
+		seriesRoleSyncPending.Delete(manga.ID)
+	}
+}
+
+// runSeriesRoleSync memproses antrean sinkronisasi role sampai ctx dibatalkan. Satu series bisa
+// butuh ratusan panggilan API Discord, jadi dikerjakan di sini agar tidak menahan checker.
+func runSeriesRoleSync(ctx context.Context, s *discordgo.Session, channelID string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case manga := <-seriesRoleSyncRequests:
+			seriesRoleSyncPending.Delete(manga.ID)
+			if err := syncSeriesRole(ctx, s, channelID, &manga); err != nil && ctx.Err() == nil {
+				slog.Warn("Failed to sync series role", "manga_id", manga.ID, "error", err)
+			}
+		}
+	}
+}
+
+// seriesRoleWatchers mengembalikan watcher yang seharusnya menjadi anggota role series (lihat usesSeriesRole).
+func seriesRoleWatchers(mangaID string) ([]string, error) {
+	prefs, err := GetNotificationPrefsForManga(db, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []string
+	for _, p := range prefs {
+		if p.Discord && p.usesSeriesRole() {
+			userIDs = append(userIDs, p.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// syncSeriesRole menyamakan anggota role series dengan seriesRoleWatchers. Role dibuat saat jumlah
+// watcher mencapai SERIES_ROLE_MIN_WATCHERS; di bawah itu role dicabut dari semua anggotanya.
+func syncSeriesRole(ctx context.Context, s *discordgo.Session, channelID string, manga *Manga) error {
+	userIDs, err := seriesRoleWatchers(manga.ID)
+	if err != nil {
+		return err
+	}
+	guildID, roleID, err := GetSeriesRole(db, manga.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if len(userIDs) < cfg.SeriesRoleMinWatchers {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		userIDs = nil
+	} else if err == sql.ErrNoRows {
+		if guildID, roleID, err = createSeriesRole(ctx, s, channelID, manga); err != nil {
+			return err
+		}
+	}
+
+	members, err := GetSeriesRoleMembers(db, manga.ID)
+	if err != nil {
+		return err
+	}
+	wanted := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = true
+		if members[userID] {
+			continue
+		}
+		err := s.GuildMemberRoleAdd(guildID, userID, roleID, discordgo.WithContext(ctx))
+		if isDiscordErrorCode(err, discordgo.ErrCodeUnknownRole) {
+			// Role dihapus manual; dibuat ulang pada sinkronisasi berikutnya
+			return errors.Join(err, DeleteSeriesRole(db, manga.ID))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Misalnya user sudah keluar dari server; tetap di-mention langsung oleh notifier
+			continue
+		}
+		if err := AddSeriesRoleMember(db, manga.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	// Catatan anggota baru dihapus setelah role benar-benar dicabut, agar notifier tidak
+	// mention role selama masih ada anggota yang tidak lagi menerima notifikasi
+	for userID := range members {
+		if wanted[userID] {
+			continue
+		}
+		err := s.GuildMemberRoleRemove(guildID, userID, roleID, discordgo.WithContext(ctx))
+		if err != nil && !isDiscordErrorCode(err, discordgo.ErrCodeUnknownMember) && !isDiscordErrorCode(err, discordgo.ErrCodeUnknownRole) {
+			return err
+		}
+		if err := RemoveSeriesRoleMember(db, manga.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seriesRoleMentions mengembalikan mention role series ditambah mention langsung untuk penerima
+// yang belum menjadi anggota role. nil jika role tidak bisa dipakai: belum ada, penerimanya terlalu
+// sedikit, atau masih ada anggota role yang bukan penerima (sinkronisasi belum selesai).
+func seriesRoleMentions(manga *Manga, roleUserIDs []string) []string {
+	if cfg.SeriesRoleMinWatchers <= 0 {
+		return nil
+	}
+	requestSeriesRoleSync(manga)
+	if len(roleUserIDs) < cfg.SeriesRoleMinWatchers {
+		return nil
+	}
+	_, roleID, err := GetSeriesRole(db, manga.ID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Error("Failed to get series role", "manga_id", manga.ID, "error", err)
+		}
+		return nil
+	}
+	members, err := GetSeriesRoleMembers(db, manga.ID)
+	if err != nil {
+		slog.Error("Failed to get series role members", "manga_id", manga.ID, "error", err)
+		return nil
+	}
+	recipients := make(map[string]bool, len(roleUserIDs))
+	for _, userID := range roleUserIDs {
+		recipients[userID] = true
+	}
+	for userID := range members {
+		if !recipients[userID] {
+			return nil
+		}
+	}
+	mentions := []string{fmt.Sprintf("<@&%s>", roleID)}
+	for _, userID := range roleUserIDs {
+		if !members[userID] {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+		}
+	}
+	return mentions
+}
+
+func createSeriesRole(ctx context.Context, s *discordgo.Session, channelID string, manga *Manga) (guildID, roleID string, err error) {
+	channel, err := s.State.Channel(channelID)
+	if err != nil {
+		if channel, err = s.Channel(channelID, discordgo.WithContext(ctx)); err != nil {
+			return "", "", err
+		}
+	}
+	mentionable := true
+	role, err := s.GuildRoleCreate(channel.GuildID, &discordgo.RoleParams{
+		Name:        truncateTitle("📚 "+manga.Title, 100),
+		Mentionable: &mentionable,
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return "", "", err
+	}
+	if err := SetSeriesRole(db, manga.ID, channel.GuildID, role.ID); err != nil {
+		return "", "", err
+	}
+	slog.Info("Series role created", "manga_id", manga.ID, "role_id", role.ID)
+	return channel.GuildID, role.ID, nil
+}
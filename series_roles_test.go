@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeriesRoleMentions(t *testing.T) {
+	previous := cfg
+	cfg = &Config{SeriesRoleMinWatchers: 2}
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		name       string
+		role       bool
+		members    []string
+		recipients []string
+		want       []string
+	}{
+		{"role not created yet", false, nil, []string{"a", "b"}, nil},
+		{"below minimum", true, []string{"a"}, []string{"a"}, nil},
+		{"all recipients are members", true, []string{"a", "b"}, []string{"a", "b"}, []string{"<@&role>"}},
+		{"new watcher mentioned directly", true, []string{"a", "b"}, []string{"a", "b", "c"}, []string{"<@&role>", "<@c>"}},
+		// b masih anggota role tapi bukan penerima: mention role akan mem-ping b
+		{"stale member falls back", true, []string{"a", "b"}, []string{"a", "c"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			manga := &Manga{ID: "manga", Title: "Manga"}
+			if tt.role {
+				if err := SetSeriesRole(db, manga.ID, "guild", "role"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, userID := range tt.members {
+				if err := AddSeriesRoleMember(db, manga.ID, userID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := seriesRoleMentions(manga, tt.recipients); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("seriesRoleMentions() = %q, want %q", got, tt.want)
+			}
+			seriesRoleSyncPending.Delete(manga.ID)
+			select {
+			case <-seriesRoleSyncRequests:
+			default:
+				t.Error("expected a role sync to be queued")
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// status.go (Riwayat pengecekan update: /status dan endpoint HTTP /status)
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	statusRunLimit     = 5
+	statusFailingLimit = 10
+)
+
+func statusCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		logFor(i).Error("Could not defer /status", "error", err)
+		return
+	}
+
+	runs, err := GetRecentCheckRuns(db, statusRunLimit)
+	if err != nil {
+		logFor(i).Error("Failed to get check runs", "error", err)
+		editInteractionContent(s, i, "Gagal membaca riwayat pengecekan.")
+		return
+	}
+	failing, err := GetFailingSeries(db, statusFailingLimit)
+	if err != nil {
+		logFor(i).Error("Failed to get failing series", "error", err)
+		editInteractionContent(s, i, "Gagal membaca riwayat pengecekan.")
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "📊 Status Pengecekan Update",
+		Color:  0x00bfff,
+		Footer: &discordgo.MessageEmbedFooter{Text: "Eveeze Comic Bot", IconURL: "https://i.imgur.com/R4Ifj2p.png"},
+	}
+	if len(runs) == 0 {
+		embed.Description = "Belum ada pengecekan yang tercatat."
+	}
+
+	var runLines []string
+	for _, run := range runs {
+		var line string
+		switch {
+		case run.FinishedAt == nil:
+			line = fmt.Sprintf("🔄 <t:%d:R> — sedang berjalan", run.StartedAt.Unix())
+		case run.Error != "":
+			line = fmt.Sprintf("❌ <t:%d:R> — %s", run.StartedAt.Unix(), errorCategoryLabel(run.ErrorCategory))
+		default:
+			line = fmt.Sprintf("✅ <t:%d:R> — %s, %d series, %d chapter baru, %d gagal",
+				run.StartedAt.Unix(), run.FinishedAt.Sub(run.StartedAt), run.SeriesChecked, run.NewChapters, run.Failures)
+		}
+		runLines = append(runLines, line)
+	}
+	if len(runLines) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Pengecekan Terakhir", Value: strings.Join(runLines, "\n")})
+	}
+
+	var failingLines []string
+	for _, f := range failing {
+		title := f.MangaTitle
+		if title == "" {
+			title = f.MangaID
+		}
+		failingLines = append(failingLines, fmt.Sprintf("**%s** — gagal %dx berturut-turut sejak <t:%d:R>\n└ %s",
+			truncateTitle(title, 40), f.ConsecutiveFailures, f.FirstFailureAt.Unix(), errorCategoryLabel(f.ErrorCategory)))
+	}
+	if len(failingLines) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: "⚠️ Series Bermasalah", Value: truncateTitle(strings.Join(failingLines, "\n"), 1024),
+		})
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Embeds: &[]*discordgo.MessageEmbed{embed}})
+}
+
+// Endpoint HTTP /status tidak berautentikasi, jadi pesan error mentah dan judul series tidak
+// ikut dikirim; error hanya dilaporkan sebagai kategori (lihat errorCategory). Pesan mentah
+// hanya ada di log dan /admin status.
+type statusFailingSeries struct {
+	MangaID             string    `json:"manga_id"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	ErrorCategory       string    `json:"error_category"`
+	FirstFailureAt      time.Time `json:"first_failure_at"`
+	LastFailureAt       time.Time `json:"last_failure_at"`
+}
+
+func statusHTTPHandler(w http.ResponseWriter, r *http.Request) {
+	runs, err := GetRecentCheckRuns(db, statusRunLimit)
+	if err != nil {
+		slog.Error("Failed to get check runs", "error", err)
+		http.Error(w, "failed to read check runs", http.StatusInternalServerError)
+		return
+	}
+	failing, err := GetFailingSeries(db, statusFailingLimit*5)
+	if err != nil {
+		slog.Error("Failed to get failing series", "error", err)
+		http.Error(w, "failed to read failing series", http.StatusInternalServerError)
+		return
+	}
+	if runs == nil {
+		runs = []CheckRun{}
+	}
+	for idx := range runs {
+		if runs[idx].Error != "" {
+			runs[idx].Error = storedErrorCategory(runs[idx].ErrorCategory)
+		}
+	}
+	failingSeries := make([]statusFailingSeries, 0, len(failing))
+	for _, f := range failing {
+		failingSeries = append(failingSeries, statusFailingSeries{
+			MangaID:             f.MangaID,
+			ConsecutiveFailures: f.ConsecutiveFailures,
+			ErrorCategory:       storedErrorCategory(f.ErrorCategory),
+			FirstFailureAt:      f.FirstFailureAt,
+			LastFailureAt:       f.LastFailureAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"running":        checkRunning.Load(),
+		"recent_runs":    runs,
+		"failing_series": failingSeries,
+	})
+}
+
+// errorCategory memetakan error ke kategori tetap yang aman ditampilkan ke user dan endpoint
+// publik. Kategori ditentukan dari tipe error, jadi harus dipanggil sebelum error disimpan.
+func errorCategory(err error) string {
+	var statusErr *APIStatusError
+	var netErr net.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var allFailed *allSeriesFailedError
+	switch {
+	case errors.As(err, &statusErr):
+		switch statusErr.StatusCode {
+		case http.StatusNotFound:
+			return "not_found"
+		case http.StatusTooManyRequests:
+			return "rate_limited"
+		}
+		return "upstream_error"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &dnsErr), errors.As(err, &opErr):
+		return "network_error"
+	case errors.Is(err, errCheckInterrupted):
+		return "interrupted"
+	case errors.As(err, &allFailed):
+		return "all_series_failed"
+	}
+	return "internal_error"
+}
+
+// storedErrorCategory mengisi kategori kosong dari baris yang dicatat sebelum kolom error_category ada.
+func storedErrorCategory(category string) string {
+	if category == "" {
+		return "unknown"
+	}
+	return category
+}
+
+var errorCategoryLabels = map[string]string{
+	"not_found":         "series tidak ditemukan di sumber (404)",
+	"rate_limited":      "dibatasi API sumber (429)",
+	"upstream_error":    "API sumber sedang bermasalah",
+	"timeout":           "API sumber tidak merespons (timeout)",
+	"network_error":     "gangguan jaringan",
+	"interrupted":       "dihentikan karena bot dimatikan",
+	"all_series_failed": "semua series gagal dicek",
+	"internal_error":    "error internal",
+}
+
+func errorCategoryLabel(category string) string {
+	if label, ok := errorCategoryLabels[category]; ok {
+		return label
+	}
+	return "error tidak diketahui"
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorCategory(t *testing.T) {
+	refused := &url.Error{Op: "Get", URL: "https://api.example/manga", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not found", &APIStatusError{StatusCode: 404}, "not_found"},
+		{"wrapped rate limit", fmt.Errorf("get latest chapter: %w", &APIStatusError{StatusCode: 429}), "rate_limited"},
+		{"server error", &APIStatusError{StatusCode: 502}, "upstream_error"},
+		{"client timeout", &url.Error{Op: "Get", URL: "https://api.example/manga", Err: timeoutError{}}, "timeout"},
+		{"context deadline", fmt.Errorf("check: %w", context.DeadlineExceeded), "timeout"},
+		{"connection refused", refused, "network_error"},
+		{"dns failure", &net.DNSError{Err: "no such host", Name: "api.example"}, "network_error"},
+		{"shutdown", errCheckInterrupted, "interrupted"},
+		{"all series failed", &allSeriesFailedError{Failures: 3}, "all_series_failed"},
+		// Pesan yang mirip tidak cukup: kategori hanya dari tipe error
+		{"look-alike message", errors.New("API returned non-200 status code: 404"), "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCategory(tt.err); got != tt.want {
+				t.Errorf("errorCategory(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeriesFailureStoresCategory(t *testing.T) {
+	newTestDB(t)
+
+	err := fmt.Errorf("get latest chapter: %w", &APIStatusError{StatusCode: 429})
+	if _, recordErr := RecordSeriesFailure(db, "m1", err.Error(), errorCategory(err), isNotFound(err), time.Now()); recordErr != nil {
+		t.Fatal(recordErr)
+	}
+	failing, recordErr := GetFailingSeries(db, 10)
+	if recordErr != nil {
+		t.Fatal(recordErr)
+	}
+	if len(failing) != 1 || failing[0].ErrorCategory != "rate_limited" || failing[0].LastError != err.Error() {
+		t.Errorf("GetFailingSeries() = %+v", failing)
+	}
+}
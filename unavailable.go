@@ -0,0 +1,90 @@
+// unavailable.go (Series yang dihapus/dipindah dari sumber)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleUnavailableSeries menandai series tidak tersedia dan memberi tahu watcher-nya sekali saja.
+func handleUnavailableSeries(mangaID string) {
+	changed, err := MarkMangaUnavailable(db, mangaID, time.Now())
+	if err != nil {
+		slog.Error("Failed to mark manga unavailable", "manga_id", mangaID, "error", err)
+		return
+	}
+	if !changed {
+		return
+	}
+	slog.Warn("Series marked unavailable", "manga_id", mangaID, "after_404s", cfg.UnavailableAfter404s)
+
+	title, err := getMangaTitle(db, mangaID)
+	if err != nil || title == "" {
+		title = mangaID
+	}
+	n := Notification{Kind: NotificationSeriesUnavailable, Manga: &Manga{ID: mangaID, Title: title}}
+	if _, err := notifyWatchers(context.Background(), n); err != nil {
+		slog.Error("Failed to send unavailable notification", "manga_id", mangaID, "error", err)
+	}
+}
+
+func createUnavailableEmbed(title string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{Name: "⚠️ Series Tidak Tersedia"},
+		Title:       title,
+		Description: "Series ini tidak lagi ditemukan di sumber (mungkin dihapus, diganti nama, atau dipindah). Anda bisa menghapusnya dari watchlist atau mencari penggantinya.",
+		Color:       0x808080,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Eveeze Comic Bot", IconURL: "https://i.imgur.com/R4Ifj2p.png"},
+	}
+}
+
+func createUnavailableActionsRow(mangaID string) discordgo.ActionsRow {
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "🔍 Cari Pengganti", Style: discordgo.PrimaryButton, CustomID: fmt.Sprintf("search_replacement_%s", mangaID)},
+			discordgo.Button{Label: "🗑️ Hapus dari Watchlist", Style: discordgo.DangerButton, CustomID: fmt.Sprintf("unavailable_remove_%s", mangaID)},
+		},
+	}
+}
+
+// Tombol di pesan publik selalu membalas secara ephemeral agar pesan aslinya tidak berubah.
+func unavailableComponentHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		return
+	}
+	userID := interactionUserID(i)
+
+	var mangaID string
+	if strings.HasPrefix(customID, "search_replacement_") {
+		mangaID = strings.TrimPrefix(customID, "search_replacement_")
+	} else {
+		mangaID = strings.TrimPrefix(customID, "unavailable_remove_")
+	}
+	item, err := GetWatchlistItem(db, userID, mangaID)
+	if err != nil {
+		editInteractionContent(s, i, "ℹ️ Series ini tidak ada di watchlist Anda.")
+		return
+	}
+
+	if strings.HasPrefix(customID, "unavailable_remove_") {
+		s.InteractionResponseEdit(i.Interaction, createDeleteConfirmMessage(item))
+		return
+	}
+
+	response, err := createSearchResponseMessage(item.MangaTitle, 1)
+	if err != nil {
+		logFor(i).Warn("Failed to search replacement", "manga_id", mangaID, "error", err)
+		editInteractionContent(s, i, "❌ Tidak ada hasil untuk: **"+item.MangaTitle+"**. Coba `/search` dengan judul lain.")
+		return
+	}
+	s.InteractionResponseEdit(i.Interaction, response)
+}
@@ -0,0 +1,187 @@
+// unfurl.go (Preview link reader di chat)
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const maxUnfurlPerMessage = 3
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>]+`)
+
+func unfurlCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	enabled := i.ApplicationCommandData().Options[0].BoolValue()
+	msg := "✅ Preview link reader telah **diaktifkan** di server ini."
+	if !enabled {
+		msg = "✅ Preview link reader telah **dinonaktifkan** di server ini."
+	}
+	if enabled && !cfg.UnfurlEnabled {
+		msg = "❌ Preview link reader belum diaktifkan oleh operator bot."
+	} else if err := SetLinkUnfurlEnabled(db, i.GuildID, enabled); err != nil {
+		logFor(i).Error("Failed to update unfurl setting", "error", err)
+		msg = "Gagal menyimpan pengaturan."
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: msg, Flags: discordgo.MessageFlagsEphemeral},
+	})
+}
+
+func messageCreateHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author == nil || m.Author.Bot || m.GuildID == "" {
+		return
+	}
+	if !strings.Contains(m.Content, cfg.ReaderBaseURL) {
+		return
+	}
+	enabled, err := IsLinkUnfurlEnabled(db, m.GuildID)
+	if err != nil {
+		slog.Error("Failed to get unfurl setting", "guild_id", m.GuildID, "error", err)
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, rawURL := range linkPattern.FindAllString(m.Content, -1) {
+		if len(seen) >= maxUnfurlPerMessage {
+			break
+		}
+		link, err := parseReaderLink(rawURL)
+		if err != nil || seen[link.MangaID+link.ChapterID] {
+			continue
+		}
+		seen[link.MangaID+link.ChapterID] = true
+
+		manga, chapter, err := resolveReaderLink(link)
+		if err != nil {
+			slog.Warn("Failed to resolve unfurl link", "guild_id", m.GuildID, "link", rawURL, "error", err)
+			continue
+		}
+		_, err = s.ChannelMessageSendComplex(m.ChannelID, createUnfurlMessage(manga, chapter, m.Reference()))
+		if err != nil {
+			slog.Error("Failed to send unfurl", "guild_id", m.GuildID, "link", rawURL, "error", err)
+		}
+	}
+}
+
+func createUnfurlMessage(manga *Manga, chapter *Chapter, reference *discordgo.MessageReference) *discordgo.MessageSend {
+	embed := &discordgo.MessageEmbed{
+		Title:       manga.Title,
+		URL:         fmt.Sprintf("%s/manga/%s", cfg.ReaderBaseURL, manga.ID),
+		Description: truncateTitle(manga.Description, 300),
+		Color:       0x00bfff,
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: manga.CoverURL},
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Eveeze Comic Bot", IconURL: "https://i.imgur.com/R4Ifj2p.png"},
+	}
+
+	// Link manga: tampilkan chapter terbaru sebagai titik "baca sampai sini"
+	if chapter == nil {
+		latestChapter, err := GetLatestChapter(manga.ID)
+		if err == nil {
+			chapter = latestChapter
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Chapter Terbaru", Value: fmt.Sprintf("%.1f", chapter.Number), Inline: true})
+		}
+	} else {
+		embed.Title = fmt.Sprintf("%s - Chapter %.1f", manga.Title, chapter.Number)
+		embed.URL = fmt.Sprintf("%s/chapter/%s", cfg.ReaderBaseURL, chapter.ID)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Chapter", Value: fmt.Sprintf("%.1f", chapter.Number), Inline: true})
+	}
+	if chapter != nil {
+		if releaseTime, err := time.Parse(time.RFC3339, chapter.ReleaseDate); err == nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Tanggal Rilis", Value: discordTimestamp(releaseTime), Inline: true})
+		}
+	}
+
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{Label: "➕ Watch", Style: discordgo.SuccessButton, CustomID: fmt.Sprintf("unfurl_watch_%s", manga.ID)},
+	}
+	if chapter != nil {
+		buttons = append(buttons, discordgo.Button{
+			Label: "✅ Tandai Dibaca Sampai Sini", Style: discordgo.PrimaryButton,
+			CustomID: fmt.Sprintf("unfurl_read_%s:%s", manga.ID, chapter.ID),
+		})
+	}
+
+	return &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+		Reference:  reference,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	}
+}
+
+// parseUnfurlCustomID membaca custom ID "unfurl_watch_<mangaID>" atau
+// "unfurl_read_<mangaID>:<chapterID>"; ID bisa mengandung "_" sehingga dipisah dengan ":".
+func parseUnfurlCustomID(customID string) (action, mangaID, chapterID string, ok bool) {
+	if rest, found := strings.CutPrefix(customID, "unfurl_watch_"); found {
+		return "watch", rest, "", rest != ""
+	}
+	if rest, found := strings.CutPrefix(customID, "unfurl_read_"); found {
+		mangaID, chapterID, _ = strings.Cut(rest, ":")
+		return "read", mangaID, chapterID, mangaID != "" && chapterID != ""
+	}
+	return "", "", "", false
+}
+
+func unfurlComponentHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		return
+	}
+	userID := interactionUserID(i)
+
+	action, mangaID, chapterID, ok := parseUnfurlCustomID(customID)
+	if !ok {
+		return
+	}
+	manga, err := GetMangaDetails(mangaID)
+	if err != nil || manga.ID == "" {
+		editInteractionContent(s, i, "❌ Manga tidak ditemukan.")
+		return
+	}
+
+	_, err = GetWatchlistItem(db, userID, manga.ID)
+	alreadyWatched := err == nil
+	if !alreadyWatched {
+		if err := watchManga(userID, manga); err != nil {
+			logFor(i).Error("Failed to add to watchlist via unfurl", "manga_id", manga.ID, "error", err)
+			editInteractionContent(s, i, "Gagal menambahkan ke watchlist.")
+			return
+		}
+	}
+
+	if action == "watch" {
+		if alreadyWatched {
+			editInteractionContent(s, i, fmt.Sprintf("ℹ️ **%s** sudah ada di watchlist Anda.", manga.Title))
+		} else {
+			editInteractionContent(s, i, fmt.Sprintf("✅ **%s** berhasil ditambahkan ke watchlist!", manga.Title))
+		}
+		return
+	}
+
+	chapter, err := GetChapterDetails(chapterID)
+	if err != nil {
+		logFor(i).Warn("Failed to get chapter for unfurl", "chapter_id", chapterID, "error", err)
+		editInteractionContent(s, i, "❌ Chapter tidak ditemukan.")
+		return
+	}
+	if err := UpdateUserProgress(db, userID, manga.ID, chapter.ID, chapter.Number); err != nil {
+		logFor(i).Error("Failed to update user progress via unfurl", "manga_id", manga.ID, "error", err)
+		editInteractionContent(s, i, "Gagal memperbarui progres.")
+		return
+	}
+	editInteractionContent(s, i, fmt.Sprintf("✅ Progres **%s** diperbarui ke chapter **%.1f**.", manga.Title, chapter.Number))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestUnfurlCustomIDRoundTrip(t *testing.T) {
+	previous := cfg
+	cfg = &Config{ReaderBaseURL: "https://reader.example"}
+	t.Cleanup(func() { cfg = previous })
+
+	manga := &Manga{ID: "solo_leveling", Title: "Solo Leveling"}
+	chapter := &Chapter{ID: "ch_200_en", Number: 200}
+	msg := createUnfurlMessage(manga, chapter, nil)
+
+	buttons := msg.Components[0].(discordgo.ActionsRow).Components
+	want := []struct{ action, chapterID string }{{"watch", ""}, {"read", chapter.ID}}
+	if len(buttons) != len(want) {
+		t.Fatalf("got %d buttons, want %d", len(buttons), len(want))
+	}
+	for idx, component := range buttons {
+		customID := component.(discordgo.Button).CustomID
+		action, mangaID, chapterID, ok := parseUnfurlCustomID(customID)
+		if !ok || action != want[idx].action || mangaID != manga.ID || chapterID != want[idx].chapterID {
+			t.Errorf("parseUnfurlCustomID(%q) = %q, %q, %q, %v", customID, action, mangaID, chapterID, ok)
+		}
+	}
+
+	if _, _, _, ok := parseUnfurlCustomID("unfurl_read_solo_leveling"); ok {
+		t.Error("parseUnfurlCustomID accepted a read button without chapter ID")
+	}
+}
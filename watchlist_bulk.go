@@ -0,0 +1,317 @@
+// watchlist_bulk.go (Mode bulk untuk watchlist)
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const bulkPageSize = 25 // Batas opsi select menu Discord
+
+var (
+	// Pilihan bulk per user: userID -> mangaID -> judul
+	bulkSelections = make(map[string]map[string]string)
+	bulkMutex      = &sync.Mutex{}
+)
+
+func watchlistBulkHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		logFor(i).Error("Could not defer /watchlist bulk", "error", err)
+		return
+	}
+	userID := interactionUserID(i)
+
+	bulkMutex.Lock()
+	delete(bulkSelections, userID)
+	bulkMutex.Unlock()
+
+	response, err := createBulkResponseMessage(userID, 1, "")
+	if err != nil {
+		logFor(i).Error("Error creating bulk response", "error", err)
+		content := "Gagal mengambil watchlist."
+		response = &discordgo.WebhookEdit{Content: &content}
+	}
+	s.InteractionResponseEdit(i.Interaction, response)
+}
+
+func createBulkResponseMessage(userID string, page int, notice string) (*discordgo.WebhookEdit, error) {
+	items, totalItems, err := GetWatchlistForUserPaginated(db, userID, page, bulkPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if totalItems == 0 {
+		content := "📚 Watchlist Anda masih kosong."
+		if notice != "" {
+			content = notice + "\n\n" + content
+		}
+		return &discordgo.WebhookEdit{Content: &content, Components: &[]discordgo.MessageComponent{}, Embeds: &[]*discordgo.MessageEmbed{}}, nil
+	}
+
+	bulkMutex.Lock()
+	selected := bulkSelections[userID]
+	selectedCount := len(selected)
+	var options []discordgo.SelectMenuOption
+	for _, item := range items {
+		description := fmt.Sprintf("Progres: chapter %.1f", item.UserProgressChapterNumber)
+		if item.Shelf != "" {
+			description += fmt.Sprintf(" • Rak: %s", item.Shelf)
+		}
+		_, isSelected := selected[item.MangaID]
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncateTitle(item.MangaTitle, 100),
+			Description: truncateTitle(description, 100),
+			Value:       item.MangaID,
+			Default:     isSelected,
+		})
+	}
+	bulkMutex.Unlock()
+
+	content := fmt.Sprintf("🧹 **Mode Bulk** — **%d** series dipilih. Pilih series di bawah, lalu pilih aksi.", selectedCount)
+	if notice != "" {
+		content = notice + "\n\n" + content
+	}
+
+	minValues := 0
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    fmt.Sprintf("bulk_select_%d", page),
+					Placeholder: "Pilih series",
+					MinValues:   &minValues,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "🗑️ Hapus", Style: discordgo.DangerButton, CustomID: "bulk_delete", Disabled: selectedCount == 0},
+				discordgo.Button{Label: "✅ Tandai Terbaru", Style: discordgo.SuccessButton, CustomID: "bulk_latest", Disabled: selectedCount == 0},
+				discordgo.Button{Label: "📁 Pindah ke Rak", Style: discordgo.PrimaryButton, CustomID: "bulk_shelf", Disabled: selectedCount == 0},
+			},
+		},
+	}
+
+	totalPages := (totalItems + bulkPageSize - 1) / bulkPageSize
+	if totalPages > 1 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "◀️ Sebelumnya", Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("bulk_page_%d", page-1), Disabled: page <= 1},
+				discordgo.Button{Label: fmt.Sprintf("%d / %d", page, totalPages), Style: discordgo.SecondaryButton, CustomID: "bulk_page_indicator", Disabled: true},
+				discordgo.Button{Label: "Berikutnya ▶️", Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("bulk_page_%d", page+1), Disabled: page >= totalPages},
+			},
+		})
+	}
+
+	return &discordgo.WebhookEdit{Content: &content, Embeds: &[]*discordgo.MessageEmbed{}, Components: &components}, nil
+}
+
+func bulkComponentHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	userID := interactionUserID(i)
+
+	// Tombol rak membuka modal, jadi tidak boleh di-defer lebih dulu
+	if customID == "bulk_shelf" {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: &discordgo.InteractionResponseData{
+				CustomID: "bulk_shelf_modal",
+				Title:    "Pindah ke Rak",
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							discordgo.TextInput{
+								CustomID:    "shelf",
+								Label:       "Nama rak (kosongkan untuk mengeluarkan)",
+								Style:       discordgo.TextInputShort,
+								Placeholder: "contoh: Selesai, Nanti Dibaca",
+								Required:    false,
+								MaxLength:   50,
+							},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			logFor(i).Error("Could not open shelf modal", "error", err)
+		}
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+
+	page := 1
+	var notice string
+	var deletedAt int64
+	switch {
+	case strings.HasPrefix(customID, "bulk_select_"):
+		page, _ = strconv.Atoi(strings.TrimPrefix(customID, "bulk_select_"))
+		items, _, err := GetWatchlistForUserPaginated(db, userID, page, bulkPageSize)
+		if err != nil {
+			logFor(i).Error("Failed to get bulk page", "page", page, "error", err)
+			return
+		}
+		titles := make(map[string]string)
+		for _, item := range items {
+			titles[item.MangaID] = item.MangaTitle
+		}
+		bulkMutex.Lock()
+		selected, ok := bulkSelections[userID]
+		if !ok {
+			selected = make(map[string]string)
+			bulkSelections[userID] = selected
+		}
+		// Pilihan di halaman ini menggantikan pilihan sebelumnya untuk halaman yang sama
+		for mangaID := range titles {
+			delete(selected, mangaID)
+		}
+		for _, mangaID := range i.MessageComponentData().Values {
+			if title, ok := titles[mangaID]; ok {
+				selected[mangaID] = title
+			}
+		}
+		bulkMutex.Unlock()
+	case strings.HasPrefix(customID, "bulk_page_"):
+		page, _ = strconv.Atoi(strings.TrimPrefix(customID, "bulk_page_"))
+	case customID == "bulk_delete":
+		notice, deletedAt = runBulkDelete(userID)
+	case customID == "bulk_latest":
+		notice = runBulkMarkLatest(userID)
+	}
+
+	response, err := createBulkResponseMessage(userID, page, notice)
+	if err != nil {
+		logFor(i).Error("Error creating bulk response", "error", err)
+		return
+	}
+	if deletedAt != 0 {
+		components := append(*response.Components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{undoButton(deletedAt)},
+		})
+		response.Components = &components
+	}
+	s.InteractionResponseEdit(i.Interaction, response)
+}
+
+func bulkModalHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	userID := interactionUserID(i)
+
+	var shelf string
+	for _, row := range i.ModalSubmitData().Components {
+		actionsRow, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actionsRow.Components {
+			if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == "shelf" {
+				shelf = strings.TrimSpace(input.Value)
+			}
+		}
+	}
+
+	response, err := createBulkResponseMessage(userID, 1, runBulkSetShelf(userID, shelf))
+	if err != nil {
+		logFor(i).Error("Error creating bulk response", "error", err)
+		return
+	}
+	s.InteractionResponseEdit(i.Interaction, response)
+}
+
+// takeBulkSelection mengambil dan mengosongkan pilihan bulk milik user.
+func takeBulkSelection(userID string) ([]string, []string) {
+	bulkMutex.Lock()
+	defer bulkMutex.Unlock()
+	var mangaIDs, titles []string
+	for mangaID, title := range bulkSelections[userID] {
+		mangaIDs = append(mangaIDs, mangaID)
+		titles = append(titles, title)
+	}
+	delete(bulkSelections, userID)
+	return mangaIDs, titles
+}
+
+func runBulkDelete(userID string) (string, int64) {
+	mangaIDs, titles := takeBulkSelection(userID)
+	if len(mangaIDs) == 0 {
+		return "⚠️ Tidak ada series yang dipilih.", 0
+	}
+	deletedAt := time.Now().UnixMilli()
+	deleted, err := BulkDeleteFromWatchlist(db, userID, mangaIDs, deletedAt)
+	if err != nil {
+		slog.Error("Failed to bulk delete watchlist", "user_id", userID, "error", err)
+		return "❌ Gagal menghapus series. Tidak ada perubahan yang disimpan.", 0
+	}
+	return fmt.Sprintf("🗑️ **%d** series dihapus dari watchlist (bisa di-undo dalam %.0f menit):\n%s",
+		deleted, undoWindow.Minutes(), bulkTitleList(titles)), deletedAt
+}
+
+func runBulkMarkLatest(userID string) string {
+	mangaIDs, titles := takeBulkSelection(userID)
+	if len(mangaIDs) == 0 {
+		return "⚠️ Tidak ada series yang dipilih."
+	}
+
+	chapters := make(map[string]*Chapter)
+	var updated, failed []string
+	for idx, mangaID := range mangaIDs {
+		latestChapter, err := GetLatestChapter(mangaID)
+		if err != nil {
+			slog.Warn("Failed to get latest chapter for bulk mark_latest", "user_id", userID, "manga_id", mangaID, "error", err)
+			failed = append(failed, titles[idx])
+			continue
+		}
+		chapters[mangaID] = latestChapter
+		updated = append(updated, titles[idx])
+	}
+	if len(chapters) > 0 {
+		if err := BulkUpdateUserProgress(db, userID, chapters); err != nil {
+			slog.Error("Failed to bulk update progress", "user_id", userID, "error", err)
+			return "❌ Gagal memperbarui progres. Tidak ada perubahan yang disimpan."
+		}
+	}
+
+	msg := fmt.Sprintf("✅ **%d** series ditandai sampai chapter terbaru:\n%s", len(updated), bulkTitleList(updated))
+	if len(failed) > 0 {
+		msg += fmt.Sprintf("\n❌ **%d** series gagal (chapter terbaru tidak bisa diambil):\n%s", len(failed), bulkTitleList(failed))
+	}
+	return msg
+}
+
+func runBulkSetShelf(userID, shelf string) string {
+	mangaIDs, titles := takeBulkSelection(userID)
+	if len(mangaIDs) == 0 {
+		return "⚠️ Tidak ada series yang dipilih."
+	}
+	if err := BulkSetShelf(db, userID, mangaIDs, shelf); err != nil {
+		slog.Error("Failed to bulk set shelf", "user_id", userID, "error", err)
+		return "❌ Gagal memindahkan series. Tidak ada perubahan yang disimpan."
+	}
+	if shelf == "" {
+		return fmt.Sprintf("📁 **%d** series dikeluarkan dari rak:\n%s", len(mangaIDs), bulkTitleList(titles))
+	}
+	return fmt.Sprintf("📁 **%d** series dipindah ke rak **%s**:\n%s", len(mangaIDs), shelf, bulkTitleList(titles))
+}
+
+func bulkTitleList(titles []string) string {
+	var lines []string
+	for idx, title := range titles {
+		if idx >= 10 {
+			lines = append(lines, fmt.Sprintf("...dan %d lainnya", len(titles)-idx))
+			break
+		}
+		lines = append(lines, "• "+truncateTitle(title, 60))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,662 @@
+// watchlist_io.go (Import dan export watchlist)
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxImportFileSize  = 2 << 20 // 2 MB
+	maxImportEntries   = 500
+	maxImportSelectOpt = 25 // Batas opsi select menu Discord
+	// Sesi konfirmasi import yang tidak diselesaikan dibuang setelah importSessionTTL
+	importSessionTTL = 30 * time.Minute
+	// Token interaksi Discord berlaku 15 menit; setelah itu hasil import dikirim lewat DM
+	interactionTokenTTL = 14 * time.Minute
+	// Progres dicari sampai sekian halaman daftar chapter (100 chapter per halaman)
+	maxProgressLookupPages = 20
+)
+
+type watchlistExportEntry struct {
+	MangaID       string  `json:"manga_id"`
+	Title         string  `json:"title"`
+	ChapterID     string  `json:"progress_chapter_id"`
+	ChapterNumber float64 `json:"progress_chapter_number"`
+}
+
+// Format export MyAnimeList (hanya field yang kita butuhkan). Export hanya berisi judul:
+// ID MyAnimeList tidak kita ketahui, jadi elemen manga_mangadb_id dihilangkan dan
+// importer MyAnimeList harus mencocokkan lewat judul.
+type malExport struct {
+	XMLName xml.Name   `xml:"myanimelist"`
+	MyInfo  malMyInfo  `xml:"myinfo"`
+	Manga   []malManga `xml:"manga"`
+}
+
+type malMyInfo struct {
+	UserExportType int `xml:"user_export_type"`
+	UserTotalManga int `xml:"user_total_manga"`
+}
+
+type malManga struct {
+	MangaDBID    int    `xml:"manga_mangadb_id,omitempty"`
+	Title        string `xml:"manga_title"`
+	ReadChapters int    `xml:"my_read_chapters"`
+	Status       string `xml:"my_status"`
+}
+
+type importMatch struct {
+	Entry watchlistExportEntry
+	Manga *Manga
+}
+
+type pendingImport struct {
+	Resolved   []importMatch
+	Unresolved []importMatch // Hanya tebakan, perlu konfirmasi user
+	Accepted   map[int]bool
+	NotFound   []string
+	CreatedAt  time.Time
+}
+
+var (
+	pendingImports = make(map[string]*pendingImport)
+	importMutex    = &sync.Mutex{}
+)
+
+func watchlistExportHandler(s *discordgo.Session, i *discordgo.InteractionCreate, opt *discordgo.ApplicationCommandInteractionDataOption) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		logFor(i).Error("Could not defer /watchlist export", "error", err)
+		return
+	}
+
+	format := "json"
+	if len(opt.Options) > 0 {
+		format = opt.Options[0].StringValue()
+	}
+
+	items, err := GetWatchlistForUser(db, interactionUserID(i))
+	if err != nil {
+		logFor(i).Error("Error getting watchlist for export", "error", err)
+		msg := "Gagal mengambil watchlist."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+		return
+	}
+	if len(items) == 0 {
+		msg := "📚 Watchlist Anda masih kosong."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+		return
+	}
+
+	data, filename, contentType, err := encodeWatchlist(items, format)
+	if err != nil {
+		logFor(i).Error("Error encoding watchlist export", "format", format, "error", err)
+		msg := "Gagal membuat file export."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+		return
+	}
+
+	msg := fmt.Sprintf("📦 Export watchlist Anda (%d series).", len(items))
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &msg,
+		Files:   []*discordgo.File{{Name: filename, ContentType: contentType, Reader: bytes.NewReader(data)}},
+	})
+}
+
+func encodeWatchlist(items []WatchlistItem, format string) ([]byte, string, string, error) {
+	entries := make([]watchlistExportEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, watchlistExportEntry{
+			MangaID: item.MangaID, Title: item.MangaTitle,
+			ChapterID: item.UserProgressChapterID, ChapterNumber: item.UserProgressChapterNumber,
+		})
+	}
+
+	switch format {
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"manga_id", "title", "progress_chapter_id", "progress_chapter_number"})
+		for _, e := range entries {
+			w.Write([]string{e.MangaID, e.Title, e.ChapterID, strconv.FormatFloat(e.ChapterNumber, 'f', -1, 64)})
+		}
+		w.Flush()
+		return buf.Bytes(), "watchlist.csv", "text/csv", w.Error()
+	case "mal":
+		export := malExport{MyInfo: malMyInfo{UserExportType: 2, UserTotalManga: len(entries)}}
+		for _, e := range entries {
+			export.Manga = append(export.Manga, malManga{
+				Title: e.Title, ReadChapters: int(math.Floor(e.ChapterNumber)), Status: "Reading",
+			})
+		}
+		data, err := xml.MarshalIndent(export, "", "\t")
+		if err != nil {
+			return nil, "", "", err
+		}
+		return append([]byte(xml.Header), data...), "watchlist.xml", "application/xml", nil
+	default:
+		data, err := json.MarshalIndent(entries, "", "  ")
+		return data, "watchlist.json", "application/json", err
+	}
+}
+
+func decodeWatchlist(filename string, data []byte) ([]watchlistExportEntry, error) {
+	format := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	if format != "json" && format != "csv" && format != "xml" {
+		// Tebak format dari isi file
+		trimmed := bytes.TrimSpace(data)
+		switch {
+		case bytes.HasPrefix(trimmed, []byte("[")):
+			format = "json"
+		case bytes.HasPrefix(trimmed, []byte("<")):
+			format = "xml"
+		default:
+			format = "csv"
+		}
+	}
+
+	var entries []watchlistExportEntry
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+	case "xml":
+		var export malExport
+		if err := xml.Unmarshal(data, &export); err != nil {
+			return nil, err
+		}
+		for _, m := range export.Manga {
+			entries = append(entries, watchlistExportEntry{Title: m.Title, ChapterNumber: float64(m.ReadChapters)})
+		}
+	case "csv":
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		// Kolom dicari lewat header; tanpa header, kolom pertama dianggap judul
+		columns := map[string]int{"title": 0, "manga_id": -1, "progress_chapter_id": -1, "progress_chapter_number": -1}
+		header := false
+		for idx, name := range records[0] {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, ok := columns[name]; ok {
+				columns[name] = idx
+				header = true
+			}
+		}
+		if header {
+			records = records[1:]
+		}
+		field := func(record []string, name string) string {
+			idx := columns[name]
+			if idx < 0 || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		for _, record := range records {
+			number, _ := strconv.ParseFloat(field(record, "progress_chapter_number"), 64)
+			entries = append(entries, watchlistExportEntry{
+				MangaID: field(record, "manga_id"), Title: field(record, "title"),
+				ChapterID: field(record, "progress_chapter_id"), ChapterNumber: number,
+			})
+		}
+	}
+
+	var valid []watchlistExportEntry
+	for _, e := range entries {
+		e.Title = strings.TrimSpace(e.Title)
+		if e.Title == "" && e.MangaID == "" {
+			continue
+		}
+		valid = append(valid, e)
+	}
+	return valid, nil
+}
+
+func watchlistImportHandler(s *discordgo.Session, i *discordgo.InteractionCreate, opt *discordgo.ApplicationCommandInteractionDataOption) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		logFor(i).Error("Could not defer /watchlist import", "error", err)
+		return
+	}
+
+	var attachment *discordgo.MessageAttachment
+	if len(opt.Options) > 0 && i.ApplicationCommandData().Resolved != nil {
+		attachmentID, _ := opt.Options[0].Value.(string)
+		attachment = i.ApplicationCommandData().Resolved.Attachments[attachmentID]
+	}
+	if attachment == nil {
+		editInteractionContent(s, i, "❌ File tidak ditemukan.")
+		return
+	}
+	if attachment.Size > maxImportFileSize {
+		editInteractionContent(s, i, "❌ File terlalu besar (maksimal 2 MB).")
+		return
+	}
+
+	data, err := downloadAttachment(attachment.URL)
+	if err != nil {
+		logFor(i).Error("Failed to download import file", "error", err)
+		editInteractionContent(s, i, "❌ Gagal mengunduh file.")
+		return
+	}
+	entries, err := decodeWatchlist(attachment.Filename, data)
+	if err != nil {
+		logFor(i).Warn("Failed to parse import file", "filename", attachment.Filename, "error", err)
+		editInteractionContent(s, i, "❌ Format file tidak dikenali. Gunakan JSON, CSV atau MyAnimeList XML.")
+		return
+	}
+	if len(entries) == 0 {
+		editInteractionContent(s, i, "❌ Tidak ada series di dalam file.")
+		return
+	}
+	if len(entries) > maxImportEntries {
+		editInteractionContent(s, i, fmt.Sprintf("❌ Terlalu banyak series (maksimal %d per import).", maxImportEntries))
+		return
+	}
+
+	// Pencocokan dan import bisa memakan waktu lebih lama dari batas interaksi Discord,
+	// jadi dikerjakan di background dengan laporan progres lewat importReporter
+	go matchWatchlistImport(newImportReporter(s, i), entries)
+}
+
+// importReporter menampilkan progres import di respons interaksi selama tokennya masih
+// berlaku, lalu beralih ke DM untuk pesan akhir.
+type importReporter struct {
+	s       *discordgo.Session
+	i       *discordgo.InteractionCreate
+	userID  string
+	expires time.Time
+}
+
+func newImportReporter(s *discordgo.Session, i *discordgo.InteractionCreate) *importReporter {
+	return &importReporter{s: s, i: i, userID: interactionUserID(i), expires: time.Now().Add(interactionTokenTTL)}
+}
+
+func (r *importReporter) progress(msg string) {
+	if time.Now().Before(r.expires) {
+		editInteractionContent(r.s, r.i, msg)
+	}
+}
+
+func (r *importReporter) send(edit *discordgo.WebhookEdit) {
+	if time.Now().Before(r.expires) {
+		if _, err := r.s.InteractionResponseEdit(r.i.Interaction, edit); err == nil {
+			return
+		}
+	}
+	channel, err := r.s.UserChannelCreate(r.userID)
+	if err != nil {
+		logFor(r.i).Error("Failed to open DM for import result", "error", err)
+		return
+	}
+	msg := &discordgo.MessageSend{}
+	if edit.Content != nil {
+		msg.Content = *edit.Content
+	}
+	if edit.Embeds != nil {
+		msg.Embeds = *edit.Embeds
+	}
+	if edit.Components != nil {
+		msg.Components = *edit.Components
+	}
+	if _, err := r.s.ChannelMessageSendComplex(channel.ID, msg); err != nil {
+		logFor(r.i).Error("Failed to send import result by DM", "error", err)
+	}
+}
+
+func (r *importReporter) finish(msg string) {
+	r.send(&discordgo.WebhookEdit{Content: &msg, Embeds: &[]*discordgo.MessageEmbed{}, Components: &[]discordgo.MessageComponent{}})
+}
+
+// importPause memberi jeda antar request ke API sumber; false jika bot sedang dimatikan.
+func importPause() bool {
+	select {
+	case <-appCtx.Done():
+		return false
+	case <-time.After(500 * time.Millisecond):
+		return true
+	}
+}
+
+func matchWatchlistImport(r *importReporter, entries []watchlistExportEntry) {
+	pending := &pendingImport{Accepted: make(map[int]bool)}
+	for idx, entry := range entries {
+		if idx%10 == 0 {
+			r.progress(fmt.Sprintf("🔍 Mencocokkan judul... (%d/%d)", idx, len(entries)))
+		}
+		manga, exact := matchImportEntry(entry)
+		switch {
+		case manga == nil:
+			pending.NotFound = append(pending.NotFound, entry.Title)
+		case exact:
+			pending.Resolved = append(pending.Resolved, importMatch{Entry: entry, Manga: manga})
+		default:
+			pending.Unresolved = append(pending.Unresolved, importMatch{Entry: entry, Manga: manga})
+		}
+		if !importPause() {
+			r.finish("❌ Import dihentikan karena bot sedang dimatikan. Silakan ulangi `/watchlist import`.")
+			return
+		}
+	}
+	if len(pending.Unresolved) > maxImportSelectOpt {
+		for _, m := range pending.Unresolved[maxImportSelectOpt:] {
+			pending.NotFound = append(pending.NotFound, m.Entry.Title)
+		}
+		pending.Unresolved = pending.Unresolved[:maxImportSelectOpt]
+	}
+
+	if len(pending.Unresolved) == 0 {
+		result := runWatchlistImport(r, pending.Resolved)
+		r.finish(importSummary(result, pending.NotFound))
+		return
+	}
+
+	importMutex.Lock()
+	pending.CreatedAt = time.Now()
+	pendingImports[r.userID] = pending
+	msg := createImportConfirmMessage(r.userID, pending)
+	importMutex.Unlock()
+	r.send(msg)
+}
+
+func purgeExpiredImports() {
+	importMutex.Lock()
+	defer importMutex.Unlock()
+	for userID, pending := range pendingImports {
+		if time.Since(pending.CreatedAt) > importSessionTTL {
+			delete(pendingImports, userID)
+		}
+	}
+}
+
+// matchImportEntry mengembalikan manga yang cocok dan apakah kecocokannya pasti.
+func matchImportEntry(entry watchlistExportEntry) (*Manga, bool) {
+	if entry.MangaID != "" {
+		manga, err := GetMangaDetails(entry.MangaID)
+		if err == nil && manga.ID != "" {
+			return manga, true
+		}
+	}
+	if entry.Title == "" {
+		return nil, false
+	}
+	results, err := SearchManga(entry.Title, 1)
+	if err != nil || len(results.Data) == 0 {
+		return nil, false
+	}
+	for _, manga := range results.Data {
+		if strings.EqualFold(strings.TrimSpace(manga.Title), entry.Title) {
+			return &manga, true
+		}
+	}
+	return &results.Data[0], false
+}
+
+// createImportConfirmMessage membaca pending.Accepted, jadi harus dipanggil sambil memegang importMutex.
+func createImportConfirmMessage(userID string, pending *pendingImport) *discordgo.WebhookEdit {
+	var lines []string
+	var options []discordgo.SelectMenuOption
+	for idx, m := range pending.Unresolved {
+		lines = append(lines, fmt.Sprintf("`%d.` %s → **%s**", idx+1, truncateTitle(m.Entry.Title, 40), truncateTitle(m.Manga.Title, 40)))
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncateTitle(m.Manga.Title, 100),
+			Description: truncateTitle("Dari: "+m.Entry.Title, 100),
+			Value:       strconv.Itoa(idx),
+			Default:     pending.Accepted[idx],
+		})
+	}
+
+	content := fmt.Sprintf("✅ **%d** series cocok dan siap diimpor.\n❔ **%d** series perlu konfirmasi. Pilih tebakan yang benar, lalu tekan **Impor**.",
+		len(pending.Resolved), len(pending.Unresolved))
+	if len(pending.NotFound) > 0 {
+		content += fmt.Sprintf("\n❌ **%d** series tidak ditemukan dan akan dilewati.", len(pending.NotFound))
+	}
+	embeds := []*discordgo.MessageEmbed{{
+		Title:       "Konfirmasi Import",
+		Description: strings.Join(lines, "\n"),
+		Color:       0xffa500,
+	}}
+	minValues := 0
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    fmt.Sprintf("import_select_%s", userID),
+					Placeholder: "Pilih tebakan yang benar",
+					MinValues:   &minValues,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "📥 Impor", Style: discordgo.SuccessButton, CustomID: fmt.Sprintf("import_run_%s", userID)},
+				discordgo.Button{Label: "Batal", Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("import_cancel_%s", userID)},
+			},
+		},
+	}
+	return &discordgo.WebhookEdit{Content: &content, Embeds: &embeds, Components: &components}
+}
+
+func importComponentHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	userID := interactionUserID(i)
+	if !strings.HasSuffix(customID, "_"+userID) {
+		return
+	}
+
+	importMutex.Lock()
+	pending, ok := pendingImports[userID]
+	if ok && time.Since(pending.CreatedAt) > importSessionTTL {
+		delete(pendingImports, userID)
+		ok = false
+	}
+	importMutex.Unlock()
+	if !ok {
+		msg := "❌ Sesi import sudah kedaluwarsa. Silakan ulangi `/watchlist import`."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &msg, Components: &[]discordgo.MessageComponent{}, Embeds: &[]*discordgo.MessageEmbed{},
+		})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(customID, "import_select_"):
+		importMutex.Lock()
+		pending.Accepted = make(map[int]bool)
+		for _, value := range i.MessageComponentData().Values {
+			if idx, err := strconv.Atoi(value); err == nil {
+				pending.Accepted[idx] = true
+			}
+		}
+		msg := createImportConfirmMessage(userID, pending)
+		importMutex.Unlock()
+		s.InteractionResponseEdit(i.Interaction, msg)
+	case strings.HasPrefix(customID, "import_cancel_"):
+		importMutex.Lock()
+		delete(pendingImports, userID)
+		importMutex.Unlock()
+		msg := "Import dibatalkan."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &msg, Components: &[]discordgo.MessageComponent{}, Embeds: &[]*discordgo.MessageEmbed{},
+		})
+	case strings.HasPrefix(customID, "import_run_"):
+		importMutex.Lock()
+		delete(pendingImports, userID)
+		matches := pending.Resolved
+		notFound := pending.NotFound
+		for idx, m := range pending.Unresolved {
+			if pending.Accepted[idx] {
+				matches = append(matches, m)
+			} else {
+				notFound = append(notFound, m.Entry.Title)
+			}
+		}
+		importMutex.Unlock()
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Components: &[]discordgo.MessageComponent{}, Embeds: &[]*discordgo.MessageEmbed{},
+		})
+		go func() {
+			r := newImportReporter(s, i)
+			r.finish(importSummary(runWatchlistImport(r, matches), notFound))
+		}()
+	}
+}
+
+type importResult struct {
+	Added, Skipped int
+	// Judul series yang progresnya tidak bisa dipulihkan (tetap di chapter terbaru)
+	ProgressSkipped []string
+}
+
+func runWatchlistImport(r *importReporter, matches []importMatch) importResult {
+	var result importResult
+	for idx, m := range matches {
+		if idx%5 == 0 {
+			r.progress(fmt.Sprintf("📥 Mengimpor watchlist... (%d/%d)", idx, len(matches)))
+		}
+		if _, err := GetWatchlistItem(db, r.userID, m.Manga.ID); err == nil {
+			result.Skipped++
+			continue
+		}
+		if err := watchManga(r.userID, m.Manga); err != nil {
+			logFor(r.i).Error("Failed to import watchlist entry", "manga_id", m.Manga.ID, "error", err)
+			result.Skipped++
+			continue
+		}
+		result.Added++
+		if !restoreImportedProgress(r, m) {
+			result.ProgressSkipped = append(result.ProgressSkipped, m.Manga.Title)
+		}
+		if !importPause() {
+			break
+		}
+	}
+	return result
+}
+
+// restoreImportedProgress memasang progres dari file. ID chapter hanya dipakai jika berasal
+// dari series yang sama; selain itu (file CSV tanpa ID chapter, MyAnimeList, atau series hasil
+// pencocokan judul) chapter dicari lewat nomornya. false jika progres ada tetapi tidak bisa dipulihkan.
+func restoreImportedProgress(r *importReporter, m importMatch) bool {
+	var err error
+	restored := true
+	switch {
+	case m.Entry.ChapterID != "" && m.Entry.MangaID == m.Manga.ID:
+		err = UpdateUserProgress(db, r.userID, m.Manga.ID, m.Entry.ChapterID, m.Entry.ChapterNumber)
+	case m.Entry.ChapterNumber > 0:
+		restored, err = restoreProgressByNumber(r.userID, m.Manga.ID, m.Entry.ChapterNumber)
+	}
+	if err != nil {
+		logFor(r.i).Error("Failed to restore imported progress", "manga_id", m.Manga.ID, "error", err)
+		return false
+	}
+	return restored
+}
+
+// restoreProgressByNumber memasang progres dari nomor chapter saja. watchManga sudah memasang
+// chapter terbaru, jadi progres hanya dimundurkan. false jika chapter dengan nomor tersebut tidak ada.
+func restoreProgressByNumber(userID, mangaID string, number float64) (bool, error) {
+	item, err := GetWatchlistItem(db, userID, mangaID)
+	if err != nil {
+		return false, err
+	}
+	if number >= item.UserProgressChapterNumber {
+		return true, nil
+	}
+	chapter, err := findChapterByNumber(mangaID, number)
+	if err != nil || chapter == nil {
+		return false, err
+	}
+	return true, UpdateUserProgress(db, userID, mangaID, chapter.ID, chapter.Number)
+}
+
+// findChapterByNumber menelusuri daftar chapter (urut nomor menurun); nil jika tidak ditemukan.
+func findChapterByNumber(mangaID string, number float64) (*Chapter, error) {
+	const pageSize = 100
+	for page := 1; page <= maxProgressLookupPages; page++ {
+		list, err := GetChapterList(mangaID, page, pageSize)
+		if err != nil {
+			if page > 1 {
+				// GetChapterList mengembalikan error untuk halaman kosong setelah chapter terakhir
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, chapter := range list.Data {
+			if chapter.Number == number {
+				return &chapter, nil
+			}
+		}
+		if len(list.Data) < pageSize || list.Data[len(list.Data)-1].Number < number {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+func importSummary(result importResult, notFound []string) string {
+	msg := fmt.Sprintf("✅ Import selesai! **%d** series ditambahkan, **%d** dilewati (sudah ada atau gagal).", result.Added, result.Skipped)
+	if len(notFound) > 0 {
+		msg += fmt.Sprintf("\n❌ **%d** series tidak diimpor:\n%s", len(notFound), importTitleList(notFound))
+	}
+	if len(result.ProgressSkipped) > 0 {
+		msg += fmt.Sprintf("\n⚠️ Progres **%d** series tidak ditemukan dan dibiarkan di chapter terbaru:\n%s",
+			len(result.ProgressSkipped), importTitleList(result.ProgressSkipped))
+	}
+	return msg
+}
+
+func importTitleList(titles []string) string {
+	var lines []string
+	for idx, title := range titles {
+		if idx >= 10 {
+			lines = append(lines, fmt.Sprintf("...dan %d lainnya", len(titles)-idx))
+			break
+		}
+		lines = append(lines, "• "+truncateTitle(title, 60))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func downloadAttachment(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("attachment download returned status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize))
+}
+
+func editInteractionContent(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) {
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+}
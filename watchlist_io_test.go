@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeWatchlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		want     []watchlistExportEntry
+	}{
+		{
+			name:     "json",
+			filename: "watchlist.json",
+			data:     `[{"manga_id":"m1","title":" One Piece ","progress_chapter_id":"c1","progress_chapter_number":1100}]`,
+			want:     []watchlistExportEntry{{MangaID: "m1", Title: "One Piece", ChapterID: "c1", ChapterNumber: 1100}},
+		},
+		{
+			name:     "csv with header in any column order",
+			filename: "watchlist.csv",
+			data:     "title,progress_chapter_number,manga_id\nOne Piece,12.5,m1\nBerserk,,m2\n",
+			want: []watchlistExportEntry{
+				{MangaID: "m1", Title: "One Piece", ChapterNumber: 12.5},
+				{MangaID: "m2", Title: "Berserk"},
+			},
+		},
+		{
+			name:     "csv without header uses first column as title",
+			filename: "list.csv",
+			data:     "One Piece\nBerserk\n",
+			want:     []watchlistExportEntry{{Title: "One Piece"}, {Title: "Berserk"}},
+		},
+		{
+			name:     "mal xml keeps read chapters as number only",
+			filename: "animelist.xml",
+			data: `<?xml version="1.0"?><myanimelist><manga><manga_title>Berserk</manga_title>` +
+				`<my_read_chapters>364</my_read_chapters></manga><manga><manga_title> </manga_title></manga></myanimelist>`,
+			want: []watchlistExportEntry{{Title: "Berserk", ChapterNumber: 364}},
+		},
+		{
+			name:     "format guessed from content",
+			filename: "export.txt",
+			data:     ` [{"title":"Berserk"}]`,
+			want:     []watchlistExportEntry{{Title: "Berserk"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeWatchlist(tt.filename, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("decodeWatchlist() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeWatchlist() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := decodeWatchlist("watchlist.json", []byte("{")); err == nil {
+		t.Error("decodeWatchlist() accepted invalid JSON")
+	}
+}
+
+func TestEncodeWatchlistRoundTrip(t *testing.T) {
+	items := []WatchlistItem{
+		{MangaID: "m1", MangaTitle: "One Piece", UserProgressChapterID: "c1", UserProgressChapterNumber: 1100},
+		{MangaID: "m2", MangaTitle: "Berserk, Deluxe", UserProgressChapterID: "c2", UserProgressChapterNumber: 12.5},
+	}
+	for _, format := range []string{"json", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			data, filename, _, err := encodeWatchlist(items, format)
+			if err != nil {
+				t.Fatalf("encodeWatchlist() error = %v", err)
+			}
+			got, err := decodeWatchlist(filename, data)
+			if err != nil {
+				t.Fatalf("decodeWatchlist() error = %v", err)
+			}
+			want := []watchlistExportEntry{
+				{MangaID: "m1", Title: "One Piece", ChapterID: "c1", ChapterNumber: 1100},
+				{MangaID: "m2", Title: "Berserk, Deluxe", ChapterID: "c2", ChapterNumber: 12.5},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	// Export MyAnimeList hanya membawa judul dan jumlah chapter yang dibaca (dibulatkan ke bawah)
+	data, filename, _, err := encodeWatchlist(items, "mal")
+	if err != nil {
+		t.Fatalf("encodeWatchlist(mal) error = %v", err)
+	}
+	got, err := decodeWatchlist(filename, data)
+	if err != nil {
+		t.Fatalf("decodeWatchlist(mal) error = %v", err)
+	}
+	want := []watchlistExportEntry{{Title: "One Piece", ChapterNumber: 1100}, {Title: "Berserk, Deluxe", ChapterNumber: 12}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mal round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestPurgeExpiredImports(t *testing.T) {
+	importMutex.Lock()
+	pendingImports["fresh"] = &pendingImport{CreatedAt: time.Now()}
+	pendingImports["stale"] = &pendingImport{CreatedAt: time.Now().Add(-importSessionTTL - time.Minute)}
+	importMutex.Unlock()
+	t.Cleanup(func() {
+		importMutex.Lock()
+		delete(pendingImports, "fresh")
+		delete(pendingImports, "stale")
+		importMutex.Unlock()
+	})
+
+	purgeExpiredImports()
+
+	importMutex.Lock()
+	defer importMutex.Unlock()
+	if _, ok := pendingImports["fresh"]; !ok {
+		t.Error("fresh import session was purged")
+	}
+	if _, ok := pendingImports["stale"]; ok {
+		t.Error("stale import session was kept")
+	}
+}
@@ -0,0 +1,108 @@
+// watchlist_undo.go (Konfirmasi hapus, soft-delete dan undo)
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Setelah undoWindow lewat, baris yang di-soft-delete akan di-purge permanen.
+const undoWindow = 5 * time.Minute
+
+func createDeleteConfirmMessage(item *WatchlistItem) *discordgo.WebhookEdit {
+	content := fmt.Sprintf("⚠️ Yakin ingin menghapus **%s** dari watchlist? Progres Anda (chapter **%.1f**) juga akan dihapus.",
+		item.MangaTitle, item.UserProgressChapterNumber)
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "🗑️ Ya, Hapus", Style: discordgo.DangerButton, CustomID: fmt.Sprintf("confirm_delete_%s", item.MangaID)},
+				discordgo.Button{Label: "Batal", Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("watchlist_page_1_%s", item.UserID)},
+			},
+		},
+	}
+	return &discordgo.WebhookEdit{Content: &content, Embeds: &[]*discordgo.MessageEmbed{}, Components: &components}
+}
+
+func undoButton(deletedAt int64) discordgo.Button {
+	return discordgo.Button{Label: "↩️ Undo", Style: discordgo.PrimaryButton, CustomID: fmt.Sprintf("undo_delete_%d", deletedAt)}
+}
+
+func confirmDeleteHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	mangaID := strings.TrimPrefix(customID, "confirm_delete_")
+	userID := interactionUserID(i)
+
+	item, err := GetWatchlistItem(db, userID, mangaID)
+	if err != nil {
+		logFor(i).Error("Failed to get watchlist item for delete", "manga_id", mangaID, "error", err)
+		return
+	}
+	deletedAt := time.Now().UnixMilli()
+	if err := SoftDeleteFromWatchlist(db, mangaID, userID, deletedAt); err != nil {
+		logFor(i).Error("Failed to delete from watchlist", "manga_id", mangaID, "error", err)
+		return
+	}
+
+	content := fmt.Sprintf("🗑️ **%s** dihapus dari watchlist. Anda bisa membatalkannya dalam %.0f menit.", item.MangaTitle, undoWindow.Minutes())
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				undoButton(deletedAt),
+				discordgo.Button{Label: "📚 Kembali ke Watchlist", Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("watchlist_page_1_%s", userID)},
+			},
+		},
+	}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content, Embeds: &[]*discordgo.MessageEmbed{}, Components: &components})
+}
+
+func undoDeleteHandler(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	userID := interactionUserID(i)
+	deletedAt, err := strconv.ParseInt(strings.TrimPrefix(customID, "undo_delete_"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	var restored int64
+	if time.Since(time.UnixMilli(deletedAt)) <= undoWindow {
+		restored, err = RestoreDeletedWatchlist(db, userID, deletedAt)
+		if err != nil {
+			logFor(i).Error("Failed to restore watchlist", "deleted_at", deletedAt, "error", err)
+			return
+		}
+	}
+	if restored == 0 {
+		msg := "❌ Waktu undo sudah habis, series tidak bisa dikembalikan."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &msg, Components: &[]discordgo.MessageComponent{}, Embeds: &[]*discordgo.MessageEmbed{},
+		})
+		return
+	}
+
+	response, err := createWatchlistResponseMessage(userID, 1)
+	if err != nil {
+		return
+	}
+	msg := fmt.Sprintf("↩️ **%d** series dikembalikan ke watchlist beserta progresnya.", restored)
+	if response.Content != nil {
+		msg += "\n\n" + *response.Content
+	}
+	response.Content = &msg
+	s.InteractionResponseEdit(i.Interaction, response)
+}
+
+func purgeExpiredDeletes() {
+	purged, err := PurgeDeletedWatchlist(db, time.Now().Add(-undoWindow).UnixMilli())
+	if err != nil {
+		slog.Error("Failed to purge deleted watchlist rows", "error", err)
+		return
+	}
+	if purged > 0 {
+		slog.Info("Purged deleted watchlist rows", "count", purged)
+	}
+}
@@ -0,0 +1,99 @@
+// webhook.go (Endpoint /webhooks/chapter untuk update chapter berbasis push)
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	webhookSignatureHeader = "X-Signature-256"
+	maxWebhookBodySize     = 64 << 10
+)
+
+// signWebhookBody menghasilkan nilai header "sha256=<hex>" berisi HMAC-SHA256 dari body mentah.
+// Skema yang sama dipakai untuk webhook masuk dan keluar.
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyWebhookSignature(secret string, body []byte, header string) bool {
+	return hmac.Equal([]byte(signWebhookBody(secret, body)), []byte(strings.ToLower(header)))
+}
+
+func writeWebhookResult(w http.ResponseWriter, status int, result string) {
+	webhookEventsTotal.WithLabelValues(result).Inc()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"result": result})
+}
+
+// chapterWebhookHandler menerima event chapter baru dari backend sumber dan memakai
+// pipeline notifikasi yang sama dengan checker. Polling tetap berjalan sebagai cadangan.
+func chapterWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		writeWebhookResult(w, http.StatusRequestEntityTooLarge, "invalid_body")
+		return
+	}
+	if !verifyWebhookSignature(cfg.WebhookSecret, body, r.Header.Get(webhookSignatureHeader)) {
+		slog.Warn("Rejected chapter webhook with invalid signature", "remote_addr", r.RemoteAddr)
+		writeWebhookResult(w, http.StatusUnauthorized, "invalid_signature")
+		return
+	}
+
+	var chapter Chapter
+	if err := json.Unmarshal(body, &chapter); err != nil || chapter.MangaID == "" || chapter.ID == "" {
+		writeWebhookResult(w, http.StatusBadRequest, "invalid_body")
+		return
+	}
+	log := slog.With("manga_id", chapter.MangaID, "chapter_id", chapter.ID, "chapter_number", chapter.Number)
+
+	knownChapterID, err := getLatestKnownChapter(db, chapter.MangaID)
+	if err == sql.ErrNoRows {
+		log.Debug("Chapter webhook for unwatched manga ignored")
+		writeWebhookResult(w, http.StatusAccepted, "not_watched")
+		return
+	}
+	if err != nil {
+		log.Error("Failed to get latest known chapter", "error", err)
+		writeWebhookResult(w, http.StatusInternalServerError, "error")
+		return
+	}
+	if knownChapterID == chapter.ID {
+		writeWebhookResult(w, http.StatusOK, "already_known")
+		return
+	}
+
+	found, stale, err := announceChapter(chapter.MangaID, &chapter)
+	if err != nil {
+		log.Error("Failed to announce chapter from webhook", "error", err)
+		writeWebhookResult(w, http.StatusBadGateway, "error")
+		return
+	}
+	// Ritme rilis berubah, jadwal polling berikutnya ikut dihitung ulang
+	scheduleNextCheck(chapter.MangaID, found)
+	switch {
+	case found:
+		log.Info("Chapter announced from webhook")
+		writeWebhookResult(w, http.StatusOK, "announced")
+	case stale:
+		writeWebhookResult(w, http.StatusOK, "not_new")
+	default:
+		writeWebhookResult(w, http.StatusOK, "no_watchers")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	sign := func(secret string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	const secret = "s3cret"
+	body := []byte(`{"manga_id":"m1","chapter_id":"c1"}`)
+	valid := sign(secret, body)
+	tests := []struct {
+		name   string
+		body   []byte
+		header string
+		want   bool
+	}{
+		{"valid", body, valid, true},
+		{"uppercase hex", body, "sha256=" + strings.ToUpper(strings.TrimPrefix(valid, "sha256=")), true},
+		{"missing header", body, "", false},
+		{"missing prefix", body, strings.TrimPrefix(valid, "sha256="), false},
+		{"wrong secret", body, sign("other", body), false},
+		{"tampered body", []byte(`{"manga_id":"m2","chapter_id":"c1"}`), valid, false},
+		{"truncated", body, valid[:len(valid)-2], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyWebhookSignature(secret, tt.body, tt.header); got != tt.want {
+				t.Errorf("verifyWebhookSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}